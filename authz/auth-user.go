@@ -1,6 +1,7 @@
 package authz
 
 import (
+	"context"
 	"net/http"
 	"reflect"
 
@@ -52,3 +53,30 @@ func (u *AuthUser) CheckIsUser(user db.User) error {
 
 	return echo.NewHTTPError(http.StatusForbidden, "missing permission to access this resource")
 }
+
+func (u *AuthUser) CheckIsGroupOwner(group db.Group) error {
+	if u.DbUser.ID == group.OwnerID {
+		return nil
+	}
+
+	return echo.NewHTTPError(http.StatusForbidden, "missing permission to access this resource")
+}
+
+// GroupUserChecker looks up group memberships, it is implemented by
+// db.Queries.
+type GroupUserChecker interface {
+	IsGroupUser(ctx context.Context, arg db.IsGroupUserParams) (bool, error)
+}
+
+func (u *AuthUser) CheckIsGroupMember(ctx context.Context, queries GroupUserChecker, groupId pgtype.UUID) error {
+	isMember, err := queries.IsGroupUser(ctx, db.IsGroupUserParams{GroupID: groupId, UserID: u.DbUser.ID})
+	if err != nil {
+		return err
+	}
+
+	if isMember {
+		return nil
+	}
+
+	return echo.NewHTTPError(http.StatusForbidden, "missing permission to access this resource")
+}
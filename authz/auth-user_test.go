@@ -0,0 +1,142 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGroupUserChecker struct {
+	members map[db.IsGroupUserParams]bool
+	err     error
+}
+
+func (f fakeGroupUserChecker) IsGroupUser(ctx context.Context, arg db.IsGroupUserParams) (bool, error) {
+	return f.members[arg], f.err
+}
+
+func newTestUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestCheckIsGroupOwner(t *testing.T) {
+	owner := newTestUUID(1)
+	other := newTestUUID(2)
+
+	tests := []struct {
+		name         string
+		userId       pgtype.UUID
+		group        db.Group
+		expectedCode int
+	}{
+		{
+			name:         "Owner",
+			userId:       owner,
+			group:        db.Group{ID: newTestUUID(10), OwnerID: owner},
+			expectedCode: 0,
+		},
+		{
+			name:         "Other user",
+			userId:       other,
+			group:        db.Group{ID: newTestUUID(10), OwnerID: owner},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "User without id",
+			userId:       pgtype.UUID{},
+			group:        db.Group{ID: newTestUUID(10), OwnerID: owner},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authUser := &AuthUser{DbUser: db.User{ID: tt.userId}}
+
+			err := authUser.CheckIsGroupOwner(tt.group)
+
+			if tt.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var httpErr *echo.HTTPError
+			assert.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.expectedCode, httpErr.Code)
+		})
+	}
+}
+
+func TestCheckIsGroupMember(t *testing.T) {
+	member := newTestUUID(1)
+	other := newTestUUID(2)
+	group := newTestUUID(10)
+	otherGroup := newTestUUID(11)
+	queryErr := errors.New("query failed")
+
+	checker := fakeGroupUserChecker{members: map[db.IsGroupUserParams]bool{
+		{GroupID: group, UserID: member}: true,
+	}}
+
+	tests := []struct {
+		name         string
+		userId       pgtype.UUID
+		groupId      pgtype.UUID
+		checker      fakeGroupUserChecker
+		expectedCode int
+		expectedErr  error
+	}{
+		{
+			name:    "Member",
+			userId:  member,
+			groupId: group,
+			checker: checker,
+		},
+		{
+			name:         "Member of another group",
+			userId:       member,
+			groupId:      otherGroup,
+			checker:      checker,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Not a member",
+			userId:       other,
+			groupId:      group,
+			checker:      checker,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:        "Query error",
+			userId:      member,
+			groupId:     group,
+			checker:     fakeGroupUserChecker{err: queryErr},
+			expectedErr: queryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authUser := &AuthUser{DbUser: db.User{ID: tt.userId}}
+
+			err := authUser.CheckIsGroupMember(context.Background(), tt.checker, tt.groupId)
+
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.expectedCode != 0:
+				var httpErr *echo.HTTPError
+				assert.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, tt.expectedCode, httpErr.Code)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
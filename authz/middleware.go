@@ -148,3 +148,15 @@ func syncDbAndClerkState(ctx context.Context, authUser *AuthUser, r *RequestAuth
 
 	return userID, user, nil
 }
+
+// UnlinkClerkUser removes the id of the database user from the public
+// metadata of the Clerk user. The role is kept, a user signing in again
+// afterwards starts over with a new database user.
+func UnlinkClerkUser(ctx context.Context, sub string) error {
+	rawJSON := json.RawMessage(`{"id":null}`)
+	_, err := clerkuser.UpdateMetadata(ctx, sub, &clerkuser.UpdateMetadataParams{
+		PublicMetadata: &rawJSON,
+	})
+
+	return err
+}
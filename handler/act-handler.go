@@ -1,25 +1,32 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/db"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/stat"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
 
 type ActHandler struct {
-	queries  *db.Queries
-	connPool *pgxpool.Pool
+	queries     *db.Queries
+	connPool    *pgxpool.Pool
+	statService *stat.Service
 }
 
 func NewActHandler(connPool *pgxpool.Pool) *ActHandler {
 	return &ActHandler{
-		queries:  db.New(connPool),
-		connPool: connPool,
+		queries:     db.New(connPool),
+		connPool:    connPool,
+		statService: stat.NewService(connPool),
 	}
 }
 
@@ -32,11 +39,33 @@ func registerActRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 	e.POST("/acts", h.createAct)
 	e.PUT("/acts/:id", h.updateAct)
 	e.DELETE("/acts/:id", h.deleteAct)
+	e.POST("/acts/:id/restore", h.restoreAct)
 }
 
 func (h *ActHandler) listActs(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
-	acts, err := h.queries.ListActs(ctx)
+
+	var request mapper.ListActsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	page, err := util.NewPage(request.Limit, request.Cursor, request.Sort, "artistName", "artistName", "songName", "createdAt")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	params, err := mapper.FromListRequestToListActsPage(request, page)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsPage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	acts, nextCursor, err := util.Paginate(page, acts, mapper.FromDbActToPosition(page.SortBy))
 	if err != nil {
 		return err
 	}
@@ -46,7 +75,7 @@ func (h *ActHandler) listActs(echoCtx echo.Context) error {
 		return err
 	}
 
-	return echoCtx.JSON(http.StatusOK, response)
+	return echoCtx.JSON(http.StatusOK, &mapper.ListActsPageResponse{ListActsResponse: response, NextCursor: nextCursor})
 }
 
 func (h *ActHandler) getAct(echoCtx echo.Context) error {
@@ -195,6 +224,11 @@ func (h *ActHandler) updateAct(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// deleteAct deletes the act together with its ratings in every
+// competition, which are removed from the stats, so that it can be
+// restored later. It is only deleted permanently with the hard query
+// parameter, which is refused as long as it participates in a competition
+// or has ratings, including deleted ones.
 func (h *ActHandler) deleteAct(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
@@ -202,6 +236,114 @@ func (h *ActHandler) deleteAct(echoCtx echo.Context) error {
 		return err
 	}
 
+	var request deleteObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	var act db.Act
+	if request.Hard {
+		act, err = hardDeleteAct(ctx, queries, id)
+	} else {
+		act, err = h.softDeleteAct(ctx, queries, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbActToResponse(act, make([]db.Rating, 0), make([]db.User, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *ActHandler) softDeleteAct(ctx context.Context, queries *db.Queries, id pgtype.UUID) (db.Act, error) {
+	deletedAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	act, err := queries.SoftDeleteActById(ctx, db.SoftDeleteActByIdParams{
+		ID:        id,
+		DeletedAt: deletedAt,
+	})
+	if err != nil {
+		return db.Act{}, err
+	}
+
+	ratings, err := queries.ListRatingsByActId(ctx, act.ID)
+	if err != nil {
+		return db.Act{}, err
+	}
+
+	if err := lockRatingCompetitions(ctx, queries, ratings); err != nil {
+		return db.Act{}, err
+	}
+
+	if err := softDeleteRatings(ctx, queries, h.statService, ratings, deletedAt); err != nil {
+		return db.Act{}, err
+	}
+
+	return act, nil
+}
+
+func hardDeleteAct(ctx context.Context, queries *db.Queries, id pgtype.UUID) (db.Act, error) {
+	hasRatings, err := queries.HasRatingsByActId(ctx, id)
+	if err != nil {
+		return db.Act{}, err
+	}
+
+	if hasRatings {
+		return db.Act{}, echo.NewHTTPError(http.StatusConflict, "act cannot be deleted permanently, it has ratings")
+	}
+
+	hasCompetitions, err := queries.HasCompetitionActsByActId(ctx, id)
+	if err != nil {
+		return db.Act{}, err
+	}
+
+	if hasCompetitions {
+		return db.Act{}, echo.NewHTTPError(http.StatusConflict, "act cannot be deleted permanently, it participates in competitions")
+	}
+
+	if err := queries.PurgeDeletedRatingsByActId(ctx, id); err != nil {
+		return db.Act{}, err
+	}
+
+	act, err := queries.DeleteActById(ctx, id)
+	if isForeignKeyViolation(err) {
+		return db.Act{}, echo.NewHTTPError(http.StatusConflict, "act cannot be deleted permanently, it is still referenced")
+	}
+
+	return act, err
+}
+
+// restoreAct restores a deleted act together with the ratings that were
+// deleted with it, apart from those of competitions that are still
+// deleted.
+func (h *ActHandler) restoreAct(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
 	var request singleObjectRequest
 	if err := echoCtx.Bind(&request); err != nil {
 		return err
@@ -212,11 +354,44 @@ func (h *ActHandler) deleteAct(echoCtx echo.Context) error {
 		return err
 	}
 
-	act, err := h.queries.DeleteActById(ctx, id)
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	deletedAct, err := queries.GetDeletedActByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	act, err := queries.RestoreActById(ctx, deletedAct.ID)
 	if err != nil {
 		return err
 	}
 
+	ratings, err := queries.ListRestorableRatings(ctx, db.ListRestorableRatingsParams{
+		DeletedAt: deletedAct.DeletedAt,
+		ActID:     act.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := lockRatingCompetitions(ctx, queries, ratings); err != nil {
+		return err
+	}
+
+	if _, err := restoreRatings(ctx, queries, h.statService, ratings); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
 	response, err := mapper.FromDbActToResponse(act, make([]db.Rating, 0), make([]db.User, 0))
 	if err != nil {
 		return err
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"context"
+	"os"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
 	"github.com/hyperremix/song-contest-rater-service/sse"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
@@ -10,13 +14,46 @@ type singleObjectRequest struct {
 	Id string `param:"id"`
 }
 
+// deleteObjectRequest deletes an object softly unless it is deleted
+// permanently with the hard query parameter.
+type deleteObjectRequest struct {
+	Id   string `param:"id"`
+	Hard bool   `query:"hard"`
+}
+
 func RegisterHandlerRoutes(e *echo.Group, connPool *pgxpool.Pool) {
+	publisher = newPublisher(connPool)
+	go pruneRatingEvents(context.Background(), db.New(connPool))
+
 	registerActRoutes(e, connPool)
 	registerCompetitionRoutes(e, connPool)
 	registerRatingRoutes(e, connPool)
 	registerUserRoutes(e, connPool)
 	registerParticipationRoutes(e, connPool)
 	registerStatRoutes(e, connPool)
+	registerGroupRoutes(e, connPool)
+	registerSeasonRoutes(e, connPool)
+	registerPredictionRoutes(e, connPool)
 }
 
 var broker = sse.NewBroker()
+
+// publisher distributes events to the broker of every instance. It
+// defaults to the in-memory broker which only works with a single node.
+var publisher sse.Publisher = broker
+
+const eventChannel = "song_contest_rater_events"
+
+// newPublisher returns a publisher that fans out events to all instances
+// via Postgres LISTEN/NOTIFY if SONGCONTESTRATERSERVICE_SSE_BACKEND is set
+// to "postgres" and the in-memory broker otherwise.
+func newPublisher(connPool *pgxpool.Pool) sse.Publisher {
+	if os.Getenv("SONGCONTESTRATERSERVICE_SSE_BACKEND") != "postgres" {
+		return broker
+	}
+
+	postgresPublisher := sse.NewPostgresPublisher(connPool, broker, eventChannel)
+	go postgresPublisher.Listen(context.Background())
+
+	return postgresPublisher
+}
@@ -1,25 +1,45 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/live"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/sse"
+	"github.com/hyperremix/song-contest-rater-service/stat"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/hyperremix/song-contest-rater-service/voting"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
 )
 
 type CompetitionHandler struct {
-	queries  *db.Queries
-	connPool *pgxpool.Pool
+	queries       *db.Queries
+	connPool      *pgxpool.Pool
+	liveService   *live.Service
+	votingService *voting.Service
+	statService   *stat.Service
 }
 
 func NewCompetitionHandler(connPool *pgxpool.Pool) *CompetitionHandler {
 	return &CompetitionHandler{
-		queries:  db.New(connPool),
-		connPool: connPool,
+		queries:       db.New(connPool),
+		connPool:      connPool,
+		liveService:   live.NewService(connPool),
+		votingService: voting.NewService(connPool),
+		statService:   stat.NewService(connPool),
 	}
 }
 
@@ -28,14 +48,54 @@ func registerCompetitionRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 
 	e.GET("/competitions", h.listCompetitions)
 	e.GET("/competitions/:id", h.getCompetition)
+	e.GET("/competitions/:id/scoreboard", h.getCompetitionScoreboard)
+	e.GET("/competitions/:id/export", h.exportCompetition)
 	e.POST("/competitions", h.createCompetition)
+	e.POST("/competitions/import", h.importCompetition)
 	e.PUT("/competitions/:id", h.updateCompetition)
 	e.DELETE("/competitions/:id", h.deleteCompetition)
+	e.POST("/competitions/:id/restore", h.restoreCompetition)
+	e.GET("/competitions/:id/live", h.getLiveState)
+	e.PUT("/competitions/:id/live", h.updateLiveSettings)
+	e.POST("/competitions/:id/live/advance", h.advanceLiveState)
+	e.POST("/competitions/:id/live/rewind", h.rewindLiveState)
+	e.POST("/competitions/:id/live/close", h.closeLiveState)
+	e.GET("/competitions/:id/running-order", h.getRunningOrder)
+	e.PUT("/competitions/:id/running-order", h.updateRunningOrder)
+	e.POST("/competitions/:id/running-order/swap", h.swapRunningOrder)
+	e.GET("/competitions/:id/running-order/validation", h.validateRunningOrder)
+	e.GET("/competitions/:id/categories", h.listCategories)
+	e.PUT("/competitions/:id/categories", h.updateCategories)
+	e.GET("/competitions/:id/voting", h.getVoting)
+	e.PUT("/competitions/:id/voting", h.updateVotingWindow)
+	e.POST("/competitions/:id/voting/lock", h.lockVoting)
+	e.POST("/competitions/:id/voting/unlock", h.unlockVoting)
 }
 
 func (h *CompetitionHandler) listCompetitions(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
-	competitions, err := h.queries.ListCompetitions(ctx)
+
+	var request mapper.ListCompetitionsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	page, err := util.NewPage(request.Limit, request.Cursor, request.Sort, "startTime", "startTime", "city", "country", "createdAt")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	params, err := mapper.FromListRequestToListCompetitionsPage(request, page)
+	if err != nil {
+		return err
+	}
+
+	competitions, err := h.queries.ListCompetitionsPage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	competitions, nextCursor, err := util.Paginate(page, competitions, mapper.FromDbCompetitionToPosition(page.SortBy))
 	if err != nil {
 		return err
 	}
@@ -45,7 +105,7 @@ func (h *CompetitionHandler) listCompetitions(echoCtx echo.Context) error {
 		return err
 	}
 
-	return echoCtx.JSON(http.StatusOK, response)
+	return echoCtx.JSON(http.StatusOK, &mapper.ListCompetitionsPageResponse{ListCompetitionsResponse: response, NextCursor: nextCursor})
 }
 
 func (h *CompetitionHandler) getCompetition(echoCtx echo.Context) error {
@@ -89,6 +149,83 @@ func (h *CompetitionHandler) getCompetition(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+func (h *CompetitionHandler) getCompetitionScoreboard(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	scoreboard, err := voting.Scoreboard(ctx, h.queries, competition, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSONBlob(http.StatusOK, scoreboard)
+}
+
+// exportCompetition streams every rating of a competition in the running
+// order of the acts. Rows are written to the client as they are read from
+// the database, the export is never held in memory.
+func (h *CompetitionHandler) exportCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.ExportCompetitionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.Format == "" {
+		request.Format = util.ExportFormatCsv
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	response := echoCtx.Response()
+	writer, err := util.NewExportWriter(response, request.Format, mapper.RatingExportColumns)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := h.connPool.Query(ctx, ratingExportQuery, competition.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return streamExport(response, writer, request.Format, "competition-"+request.Id, func() (util.ExportRow, error) {
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+
+		rating, err := pgx.RowToStructByName[mapper.DbRatingExportRow](rows)
+		if err != nil {
+			return nil, err
+		}
+
+		return mapper.FromDbRatingExportToExportRow(rating)
+	})
+}
+
 func (h *CompetitionHandler) createCompetition(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
@@ -103,11 +240,27 @@ func (h *CompetitionHandler) createCompetition(echoCtx echo.Context) error {
 
 	insertParams := mapper.FromCreateRequestToInsertCompetition(&request)
 
-	competition, err := h.queries.InsertCompetition(ctx, insertParams)
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.InsertCompetition(ctx, insertParams)
 	if err != nil {
 		return err
 	}
 
+	if _, err := insertCompetitionCategories(ctx, queries, competition.ID, util.DefaultCategories); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
 	response, err := mapper.FromDbCompetitionToResponse(competition)
 	if err != nil {
 		return err
@@ -116,6 +269,139 @@ func (h *CompetitionHandler) createCompetition(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusCreated, response)
 }
 
+// importCompetition creates a competition with its whole line-up in one
+// transaction. Acts that already exist with the same artist and song are
+// reused instead of created.
+func (h *CompetitionHandler) importCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.ImportCompetitionRequest
+	if err := bindImportRequest(echoCtx, &request); err != nil {
+		return err
+	}
+
+	if request.Competition == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "competition is required")
+	}
+
+	if len(request.Acts) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one act is required")
+	}
+
+	orders, err := mapper.FromImportRequestToRunningOrder(&request)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := util.ValidateRunningOrder(orders); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	seen := make(map[string]bool, len(request.Acts))
+	for _, act := range request.Acts {
+		if act.ArtistName == "" || act.SongName == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "artist and song of every act are required")
+		}
+
+		key := strings.ToLower(act.ArtistName + "\x00" + act.SongName)
+		if seen[key] {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s - %s is listed more than once", act.ArtistName, act.SongName))
+		}
+		seen[key] = true
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.InsertCompetition(ctx, mapper.FromCreateRequestToInsertCompetition(request.Competition))
+	if err != nil {
+		return err
+	}
+
+	if _, err := insertCompetitionCategories(ctx, queries, competition.ID, util.DefaultCategories); err != nil {
+		return err
+	}
+
+	acts := make([]db.Act, len(request.Acts))
+	matched := make([]bool, len(request.Acts))
+	for i, actRequest := range request.Acts {
+		act, err := queries.GetActByArtistAndSong(ctx, mapper.FromImportActRequestToGetActByArtistAndSong(actRequest))
+		switch {
+		case err == nil:
+			matched[i] = true
+		case errors.Is(err, pgx.ErrNoRows):
+			act, err = queries.InsertAct(ctx, mapper.FromImportActRequestToInsertAct(actRequest))
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if err := queries.InsertCompetitionAct(ctx, mapper.FromImportToInsertCompetitionAct(competition.ID, act.ID, orders[i])); err != nil {
+			return err
+		}
+
+		acts[i] = act
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbImportToResponse(competition, acts, orders, matched)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusCreated, response)
+}
+
+// bindImportRequest binds a line-up from a JSON body or from a multipart
+// form with the competition as JSON in the competition field and the acts
+// as a CSV file in the file field.
+func bindImportRequest(echoCtx echo.Context, request *mapper.ImportCompetitionRequest) error {
+	contentType := echoCtx.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		return echoCtx.Bind(request)
+	}
+
+	var competition pb.CreateCompetitionRequest
+	if err := json.Unmarshal([]byte(echoCtx.FormValue("competition")), &competition); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "competition must be valid JSON")
+	}
+
+	fileHeader, err := echoCtx.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	acts, err := mapper.FromCsvToImportActRequests(file)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	request.Competition = &competition
+	request.Acts = acts
+
+	return nil
+}
+
 func (h *CompetitionHandler) updateCompetition(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
@@ -152,6 +438,11 @@ func (h *CompetitionHandler) updateCompetition(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// deleteCompetition deletes the competition together with its ratings,
+// which are removed from the stats, so that it can be restored later.
+// It is only deleted permanently with the hard query parameter, which is
+// refused as long as acts participate in it or it has ratings, including
+// deleted ones.
 func (h *CompetitionHandler) deleteCompetition(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
@@ -159,6 +450,112 @@ func (h *CompetitionHandler) deleteCompetition(echoCtx echo.Context) error {
 		return err
 	}
 
+	var request deleteObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	var competition db.Competition
+	if request.Hard {
+		competition, err = hardDeleteCompetition(ctx, queries, id)
+	} else {
+		competition, err = h.softDeleteCompetition(ctx, queries, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCompetitionToResponse(competition)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *CompetitionHandler) softDeleteCompetition(ctx context.Context, queries *db.Queries, id pgtype.UUID) (db.Competition, error) {
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, id)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	if err := voting.Freeze(ctx, queries, competition, time.Now()); err != nil {
+		return db.Competition{}, err
+	}
+
+	ratings, err := queries.ListRatingsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	deletedAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	if err := softDeleteRatings(ctx, queries, h.statService, ratings, deletedAt); err != nil {
+		return db.Competition{}, err
+	}
+
+	return queries.SoftDeleteCompetitionById(ctx, db.SoftDeleteCompetitionByIdParams{
+		ID:        competition.ID,
+		DeletedAt: deletedAt,
+	})
+}
+
+func hardDeleteCompetition(ctx context.Context, queries *db.Queries, id pgtype.UUID) (db.Competition, error) {
+	hasRatings, err := queries.HasRatingsByCompetitionId(ctx, id)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	if hasRatings {
+		return db.Competition{}, echo.NewHTTPError(http.StatusConflict, "competition cannot be deleted permanently, it has ratings")
+	}
+
+	hasActs, err := queries.HasCompetitionActsByCompetitionId(ctx, id)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	if hasActs {
+		return db.Competition{}, echo.NewHTTPError(http.StatusConflict, "competition cannot be deleted permanently, acts participate in it")
+	}
+
+	if err := queries.PurgeDeletedRatingsByCompetitionId(ctx, id); err != nil {
+		return db.Competition{}, err
+	}
+
+	competition, err := queries.DeleteCompetitionById(ctx, id)
+	if isForeignKeyViolation(err) {
+		return db.Competition{}, echo.NewHTTPError(http.StatusConflict, "competition cannot be deleted permanently, it is still referenced")
+	}
+
+	return competition, err
+}
+
+// restoreCompetition restores a deleted competition together with the
+// ratings that were deleted with it.
+func (h *CompetitionHandler) restoreCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
 	var request singleObjectRequest
 	if err := echoCtx.Bind(&request); err != nil {
 		return err
@@ -169,10 +566,39 @@ func (h *CompetitionHandler) deleteCompetition(echoCtx echo.Context) error {
 		return err
 	}
 
-	competition, err := h.queries.DeleteCompetitionById(ctx, id)
+	tx, err := h.connPool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	deletedCompetition, err := queries.GetDeletedCompetitionByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := queries.RestoreCompetitionById(ctx, deletedCompetition.ID)
+	if err != nil {
+		return err
+	}
+
+	ratings, err := queries.ListRestorableRatings(ctx, db.ListRestorableRatingsParams{
+		DeletedAt:     deletedCompetition.DeletedAt,
+		CompetitionID: competition.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := restoreRatings(ctx, queries, h.statService, ratings); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
 
 	response, err := mapper.FromDbCompetitionToResponse(competition)
 	if err != nil {
@@ -181,3 +607,609 @@ func (h *CompetitionHandler) deleteCompetition(echoCtx echo.Context) error {
 
 	return echoCtx.JSON(http.StatusOK, response)
 }
+
+func (h *CompetitionHandler) getLiveState(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToLiveStateResponse(competition, acts)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *CompetitionHandler) updateLiveSettings(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateLiveSettingsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.UpdateCompetitionPerformedActsOnly(ctx, db.UpdateCompetitionPerformedActsOnlyParams{
+		ID:                id,
+		PerformedActsOnly: request.PerformedActsOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.respondWithLiveState(echoCtx, competition)
+}
+
+func (h *CompetitionHandler) advanceLiveState(echoCtx echo.Context) error {
+	return h.transitionLiveState(echoCtx, live.Advance)
+}
+
+func (h *CompetitionHandler) rewindLiveState(echoCtx echo.Context) error {
+	return h.transitionLiveState(echoCtx, live.Rewind)
+}
+
+func (h *CompetitionHandler) closeLiveState(echoCtx echo.Context) error {
+	return h.transitionLiveState(echoCtx, func(state live.State, _ []int32) (live.State, error) {
+		return live.Close(state)
+	})
+}
+
+func (h *CompetitionHandler) transitionLiveState(echoCtx echo.Context, transition func(live.State, []int32) (live.State, error)) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.liveService.Transition(ctx, id, transition)
+	if err != nil {
+		return err
+	}
+
+	return h.respondWithLiveState(echoCtx, competition)
+}
+
+// respondWithLiveState broadcasts the live state of the competition
+// to its subscribers and returns it to the caller.
+func (h *CompetitionHandler) respondWithLiveState(echoCtx echo.Context, competition db.Competition) error {
+	ctx := echoCtx.Request().Context()
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToLiveStateResponse(competition, acts)
+	if err != nil {
+		return err
+	}
+
+	publishLiveStateEvent(ctx, response)
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// publishLiveStateEvent sends the live state to all subscribers of the
+// competition, including those of every group. It is not part of the
+// rating event log and therefore has no id, clients fetch the current
+// live state when they reconnect. The live state has already been
+// written, so a failed broadcast is only logged.
+func publishLiveStateEvent(ctx context.Context, response *mapper.LiveStateResponse) {
+	log := zerolog.Ctx(ctx)
+
+	event, err := sse.NewEvent(sse.EventOptions{
+		Event: "liveStateChanged",
+		Data:  response,
+		Retry: eventRetry,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("error creating live state event")
+		return
+	}
+
+	err = publisher.Publish(ctx, sse.Message{
+		Topic: sse.Topic{CompetitionId: response.CompetitionId, AllGroups: true},
+		Event: event,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("error publishing live state event")
+	}
+}
+
+func (h *CompetitionHandler) getRunningOrder(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToRunningOrderResponse(competition.ID, acts)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updateRunningOrder replaces the whole running order. Every act of the
+// competition has to be listed exactly once and is ordered by its
+// position in the list.
+func (h *CompetitionHandler) updateRunningOrder(echoCtx echo.Context) error {
+	var request mapper.UpdateRunningOrderRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	actIds, err := mapper.FromUpdateRequestToRunningOrder(&request)
+	if err != nil {
+		return err
+	}
+
+	return h.changeRunningOrder(echoCtx, request.Id, func(acts []db.ListActsByCompetitionIdRow) (map[pgtype.UUID]int32, error) {
+		if len(actIds) != len(acts) {
+			return nil, errors.New("every act of the competition has to be ordered")
+		}
+
+		orders := make(map[pgtype.UUID]int32, len(actIds))
+		for i, actId := range actIds {
+			orders[actId] = int32(i + 1)
+		}
+
+		for _, act := range acts {
+			if _, ok := orders[act.ID]; !ok {
+				return nil, errors.New("act does not participate in the competition")
+			}
+		}
+
+		return orders, nil
+	})
+}
+
+// swapRunningOrder exchanges the order of two acts and leaves the rest of
+// the running order as it is.
+func (h *CompetitionHandler) swapRunningOrder(echoCtx echo.Context) error {
+	var request mapper.SwapRunningOrderRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	first, second, err := mapper.FromSwapRequestToActIds(&request)
+	if err != nil {
+		return err
+	}
+
+	return h.changeRunningOrder(echoCtx, request.Id, func(acts []db.ListActsByCompetitionIdRow) (map[pgtype.UUID]int32, error) {
+		current := make(map[pgtype.UUID]pgtype.Int4, len(acts))
+		for _, act := range acts {
+			current[act.ID] = act.Order
+		}
+
+		firstOrder, firstOk := current[first]
+		secondOrder, secondOk := current[second]
+		if !firstOk || !secondOk {
+			return nil, errors.New("act does not participate in the competition")
+		}
+
+		if !firstOrder.Valid || !secondOrder.Valid {
+			return nil, errors.New("only acts with an order can be swapped")
+		}
+
+		return map[pgtype.UUID]int32{
+			first:  secondOrder.Int32,
+			second: firstOrder.Int32,
+		}, nil
+	})
+}
+
+// changeRunningOrder applies the orders returned by reorder in a single
+// transaction and broadcasts the new running order to the subscribers of
+// the competition. Acts missing from the returned orders keep theirs.
+// Acts that have already performed cannot be moved and no act can be
+// moved into their positions.
+func (h *CompetitionHandler) changeRunningOrder(echoCtx echo.Context, competitionId string, reorder func([]db.ListActsByCompetitionIdRow) (map[pgtype.UUID]int32, error)) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(competitionId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	acts, err := queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	orders, err := reorder(acts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	state := live.FromCompetition(competition)
+	for _, act := range acts {
+		order, ok := orders[act.ID]
+		if !ok || (act.Order.Valid && act.Order.Int32 == order) {
+			continue
+		}
+
+		if !live.CanReorder(state, act.Order, pgtype.Int4{Int32: order, Valid: true}) {
+			return echo.NewHTTPError(http.StatusConflict, "acts that have already performed cannot be reordered")
+		}
+
+		err := queries.UpdateCompetitionActOrder(ctx, db.UpdateCompetitionActOrderParams{
+			CompetitionID: competition.ID,
+			ActID:         act.ID,
+			Order:         pgtype.Int4{Int32: order, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	acts, err = queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToRunningOrderResponse(competition.ID, acts)
+	if err != nil {
+		return err
+	}
+
+	publishRunningOrderEvent(ctx, response)
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// publishRunningOrderEvent sends the running order to all subscribers of
+// the competition, including those of every group, so they can re-sort
+// the acts. Like the live state it has no id, clients fetch the running
+// order when they reconnect, and a failed broadcast is only logged.
+func publishRunningOrderEvent(ctx context.Context, response *mapper.RunningOrderResponse) {
+	log := zerolog.Ctx(ctx)
+
+	event, err := sse.NewEvent(sse.EventOptions{
+		Event: "runningOrderChanged",
+		Data:  response,
+		Retry: eventRetry,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("error creating running order event")
+		return
+	}
+
+	err = publisher.Publish(ctx, sse.Message{
+		Topic: sse.Topic{CompetitionId: response.CompetitionId, AllGroups: true},
+		Event: event,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("error publishing running order event")
+	}
+}
+
+// validateRunningOrder reports gaps, duplicates and acts without an order
+// in the running order of a competition.
+func (h *CompetitionHandler) validateRunningOrder(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToRunningOrderValidationResponse(competition.ID, acts)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *CompetitionHandler) getVoting(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return respondWithVoting(echoCtx, competition)
+}
+
+func (h *CompetitionHandler) updateVotingWindow(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateVotingWindowRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	updateVotingClosesAtParams, err := mapper.FromUpdateRequestToUpdateVotingClosesAt(&request)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.UpdateCompetitionVotingClosesAt(ctx, updateVotingClosesAtParams)
+	if err != nil {
+		return err
+	}
+
+	// Reopening the voting window thaws the scoreboard that was frozen
+	// when it passed, a lock keeps it frozen.
+	if voting.CheckIsOpen(competition, time.Now()) == nil {
+		if err := queries.DeleteCompetitionSnapshotByCompetitionId(ctx, competition.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return respondWithVoting(echoCtx, competition)
+}
+
+func (h *CompetitionHandler) lockVoting(echoCtx echo.Context) error {
+	return h.changeVotingLock(echoCtx, h.votingService.Lock)
+}
+
+func (h *CompetitionHandler) unlockVoting(echoCtx echo.Context) error {
+	return h.changeVotingLock(echoCtx, h.votingService.Unlock)
+}
+
+func (h *CompetitionHandler) changeVotingLock(echoCtx echo.Context, change func(context.Context, pgtype.UUID) (db.Competition, error)) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := change(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return respondWithVoting(echoCtx, competition)
+}
+
+func respondWithVoting(echoCtx echo.Context, competition db.Competition) error {
+	response, err := mapper.FromDbToVotingResponse(competition, voting.CheckIsOpen(competition, time.Now()) == nil)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *CompetitionHandler) listCategories(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	categories, err := h.queries.ListCompetitionCategoriesByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCategoryListToResponse(competition.ID, categories)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updateCategories replaces the categories of the competition. As the
+// totals of existing ratings would no longer match their categories, the
+// categories can only be changed before the first rating was submitted.
+func (h *CompetitionHandler) updateCategories(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateCategoriesRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	categories := mapper.FromCategoryRequestListToUtil(request.Categories)
+	if err := util.ValidateCategories(categories); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ratingCount, err := queries.CountRatingsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	if ratingCount > 0 {
+		return echo.NewHTTPError(http.StatusConflict, "categories cannot be changed after ratings were submitted, including deleted ones")
+	}
+
+	if err := queries.DeleteCompetitionCategoriesByCompetitionId(ctx, competition.ID); err != nil {
+		return err
+	}
+
+	dbCategories, err := insertCompetitionCategories(ctx, queries, competition.ID, categories)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCategoryListToResponse(competition.ID, dbCategories)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func insertCompetitionCategories(ctx context.Context, queries *db.Queries, competitionId pgtype.UUID, categories []util.Category) ([]db.CompetitionCategory, error) {
+	dbCategories := make([]db.CompetitionCategory, 0, len(categories))
+	for _, params := range mapper.FromUtilCategoryListToInsertCompetitionCategories(competitionId, categories) {
+		category, err := queries.InsertCompetitionCategory(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		dbCategories = append(dbCategories, category)
+	}
+
+	return dbCategories, nil
+}
@@ -7,10 +7,16 @@ import (
 
 	"github.com/hyperremix/song-contest-rater-service/mapper"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
 )
 
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
 func ErrorHandler(err error, c echo.Context) {
 	if c.Response().Committed {
 		return
@@ -47,6 +53,9 @@ func getCode(err error, c echo.Context) int {
 	case errors.Is(err, pgx.ErrNoRows):
 		log.Warn().Err(err).Msg("not found")
 		return http.StatusNotFound
+	case isConflict(err):
+		log.Warn().Err(err).Msg("conflict")
+		return http.StatusConflict
 
 	case errors.Is(err, pgx.ErrTxClosed),
 		errors.Is(err, pgx.ErrTxCommitRollback):
@@ -57,3 +66,19 @@ func getCode(err error, c echo.Context) int {
 		return http.StatusInternalServerError
 	}
 }
+
+// isConflict reports whether the write was rejected because it duplicates
+// another row. Foreign key violations are not conflicts in general, an
+// insert that refers to a missing row is not fixed by retrying it, so the
+// deletes that can be blocked by other rows map them themselves.
+func isConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// isForeignKeyViolation reports whether the write was rejected because of
+// a missing row or because of rows that refer to the written row.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
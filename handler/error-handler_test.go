@@ -10,6 +10,7 @@ import (
 
 	"github.com/hyperremix/song-contest-rater-service/mapper"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,6 +63,20 @@ func TestErrorHandler(t *testing.T) {
 			expectedCode:      http.StatusNotFound,
 			expectedMsg:       "no rows in result set",
 		},
+		{
+			name:              "Foreign key violation of an insert",
+			err:               &pgconn.PgError{Severity: "ERROR", Code: "23503", Message: "insert or update on table \"ratings\" violates foreign key constraint"},
+			responseCommitted: false,
+			expectedCode:      http.StatusInternalServerError,
+			expectedMsg:       "ERROR: insert or update on table \"ratings\" violates foreign key constraint (SQLSTATE 23503)",
+		},
+		{
+			name:              "Unique violation",
+			err:               &pgconn.PgError{Severity: "ERROR", Code: "23505", Message: "duplicate key value violates unique constraint"},
+			responseCommitted: false,
+			expectedCode:      http.StatusConflict,
+			expectedMsg:       "ERROR: duplicate key value violates unique constraint (SQLSTATE 23505)",
+		},
 		{
 			name:              "Transaction closed error",
 			err:               pgx.ErrTxClosed,
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ratingEventRetention is how long rating events are kept for clients
+	// to replay. Clients that reconnect after a longer time have to reload
+	// the ratings instead.
+	ratingEventRetention     = 7 * 24 * time.Hour
+	ratingEventPruneInterval = time.Hour
+)
+
+// pruneRatingEvents executes in a goroutine. It deletes the rating events
+// that are older than the retention until the given context is done.
+// Every instance prunes, deleting the same events twice is harmless.
+func pruneRatingEvents(ctx context.Context, queries *db.Queries) {
+	ticker := time.NewTicker(ratingEventPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		before := pgtype.Timestamptz{Time: time.Now().Add(-ratingEventRetention), Valid: true}
+		if err := queries.DeleteRatingEventsBefore(ctx, before); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("pruning rating events failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/authz"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/sse"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventRetry           = 10000
+	eventReplayPageSize  = 500
+	eventKeepAliveTicker = 15 * time.Second
+)
+
+// listRatingEventsFunc returns a page of persisted rating events
+// with a sequence number greater than afterId.
+type listRatingEventsFunc func(afterId int64, limit int32) ([]db.RatingEvent, error)
+
+type ratingEventsRequest struct {
+	Id            string `param:"id"`
+	CompetitionId string `query:"competitionId"`
+	ActId         string `query:"actId"`
+}
+
+// ratingEventsFilter holds the optional competition and act filters of a
+// stream both as broker topic and as database ids for replaying events.
+type ratingEventsFilter struct {
+	topic         sse.Topic
+	competitionId pgtype.UUID
+	actId         pgtype.UUID
+}
+
+func fromRequestToRatingEventsFilter(request ratingEventsRequest, groupId string) (ratingEventsFilter, error) {
+	competitionId, err := mapper.FromProtoToNullableDbId(request.CompetitionId)
+	if err != nil {
+		return ratingEventsFilter{}, err
+	}
+
+	actId, err := mapper.FromProtoToNullableDbId(request.ActId)
+	if err != nil {
+		return ratingEventsFilter{}, err
+	}
+
+	topic := sse.Topic{GroupId: groupId}
+	if competitionId.Valid {
+		topic.CompetitionId, err = mapper.FromDbToProtoId(competitionId)
+		if err != nil {
+			return ratingEventsFilter{}, err
+		}
+	}
+
+	if actId.Valid {
+		topic.ActId, err = mapper.FromDbToProtoId(actId)
+		if err != nil {
+			return ratingEventsFilter{}, err
+		}
+	}
+
+	return ratingEventsFilter{
+		topic:         topic,
+		competitionId: competitionId,
+		actId:         actId,
+	}, nil
+}
+
+func newRatingEvent(e db.RatingEvent) (sse.Event, error) {
+	return sse.NewEvent(sse.EventOptions{
+		ID:    strconv.FormatInt(e.ID, 10),
+		Event: e.Event,
+		Data:  json.RawMessage(e.Data),
+		Retry: eventRetry,
+	})
+}
+
+func newPingEvent() (sse.Event, error) {
+	return sse.NewEvent(sse.EventOptions{
+		Event: "ping",
+		Retry: eventRetry,
+		Data:  "keep-alive",
+	})
+}
+
+// getLastEventId returns the sequence number of the last event a client
+// has received. Browsers send it in the Last-Event-ID header when they
+// reconnect, the lastEventId query parameter allows clients to resume
+// with a new connection.
+func getLastEventId(echoCtx echo.Context) (int64, bool) {
+	lastEventId := echoCtx.Request().Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = echoCtx.QueryParam("lastEventId")
+	}
+
+	if lastEventId == "" {
+		return 0, false
+	}
+
+	sequence, err := strconv.ParseInt(lastEventId, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return sequence, true
+}
+
+// streamEvents subscribes the user to the given topic and streams its events
+// to the client until it disconnects. If the client sent the id of the last
+// event it received, all events it missed are replayed first. The user is
+// subscribed in between two replays so that its buffer does not overflow
+// while the client is catching up and no event gets lost either. If the
+// broker disconnects a slow client, the stream ends and the client replays
+// the missed events when it reconnects. Events commit in the order of their
+// ids, see insertRatingEvent, so no event with a smaller id than a
+// replayed one can show up later.
+func streamEvents(echoCtx echo.Context, authUser *authz.AuthUser, topic sse.Topic, listEvents listRatingEventsFunc) error {
+	ctx := echoCtx.Request().Context()
+	log := zerolog.Ctx(ctx)
+
+	echoCtx.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	echoCtx.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	echoCtx.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+
+	lastEventId, replay := getLastEventId(echoCtx)
+
+	if replay {
+		var err error
+		lastEventId, err = replayEvents(echoCtx, authUser.DbUser.ID, lastEventId, listEvents)
+		if err != nil {
+			return err
+		}
+	}
+
+	subscription := broker.Subscribe(topic, authUser.UserID)
+	defer broker.Unsubscribe(subscription)
+
+	if replay {
+		var err error
+		lastEventId, err = replayEvents(echoCtx, authUser.DbUser.ID, lastEventId, listEvents)
+		if err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(eventKeepAliveTicker)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-subscription.Events():
+			if !ok {
+				log.Warn().Msg("event stream disconnected because the client did not keep up")
+				return nil
+			}
+
+			if sequence, ok := e.Sequence(); ok && replay && sequence <= lastEventId {
+				continue
+			}
+
+			if err := e.MarshalTo(echoCtx.Response().Writer); err != nil {
+				return err
+			}
+			echoCtx.Response().Flush()
+		case <-ticker.C:
+			event, err := newPingEvent()
+			if err != nil {
+				log.Error().Err(err).Msg("error creating ping event")
+				continue
+			}
+
+			if err := event.MarshalTo(echoCtx.Response().Writer); err != nil {
+				return err
+			}
+			echoCtx.Response().Flush()
+		}
+	}
+}
+
+// replayEvents writes all persisted events after lastEventId that were
+// not authored by the user and returns the id of the last replayed event.
+func replayEvents(echoCtx echo.Context, userId pgtype.UUID, lastEventId int64, listEvents listRatingEventsFunc) (int64, error) {
+	for {
+		events, err := listEvents(lastEventId, eventReplayPageSize)
+		if err != nil {
+			return lastEventId, err
+		}
+
+		for _, e := range events {
+			lastEventId = e.ID
+			if e.UserID == userId {
+				continue
+			}
+
+			event, err := newRatingEvent(e)
+			if err != nil {
+				return lastEventId, err
+			}
+
+			if err := event.MarshalTo(echoCtx.Response().Writer); err != nil {
+				return lastEventId, err
+			}
+		}
+		echoCtx.Response().Flush()
+
+		if len(events) < eventReplayPageSize {
+			return lastEventId, nil
+		}
+	}
+}
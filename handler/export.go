@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/labstack/echo/v4"
+)
+
+// exportFlushInterval is the number of rows after which an export is
+// flushed to the client.
+const exportFlushInterval = 100
+
+// ratingExportQuery lists the ratings of a competition with their user and
+// act in the running order. It is not generated by sqlc because sqlc reads
+// all rows into a slice, the export iterates the rows instead.
+const ratingExportQuery = `SELECT r.id, r.user_id, u.firstname, u.lastname, r.act_id, a.artist_name, a.song_name, ca."order",
+	r.song, r.singing, r.show, r.looks, r.clothes, r.total, r.created_at, r.updated_at
+FROM ratings r
+LEFT JOIN users u ON u.id = r.user_id
+LEFT JOIN acts a ON a.id = r.act_id AND a.deleted_at IS NULL
+LEFT JOIN competitions_acts ca ON ca.competition_id = r.competition_id AND ca.act_id = r.act_id
+WHERE r.competition_id = $1 AND r.deleted_at IS NULL
+ORDER BY ca."order" NULLS LAST, r.created_at, r.id`
+
+// streamExport sends the headers of an export and then writes the rows
+// returned by next until it returns a nil row. The headers have to be sent
+// before the writer writes anything, writing commits the response.
+func streamExport(response *echo.Response, writer util.ExportWriter, format string, filename string, next func() (util.ExportRow, error)) error {
+	response.Header().Set(echo.HeaderContentType, util.ExportContentType(format))
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s.%s\"", filename, format))
+	response.WriteHeader(http.StatusOK)
+
+	for i := 1; ; i++ {
+		row, err := next()
+		if err != nil {
+			return err
+		}
+
+		if row == nil {
+			break
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+
+		if i%exportFlushInterval == 0 {
+			response.Flush()
+		}
+	}
+
+	return writer.Close()
+}
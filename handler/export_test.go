@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamExport(t *testing.T) {
+	rows := []util.ExportRow{
+		&mapper.RatingExportRow{RatingId: "r1", UserName: "Jane Doe", ArtistName: "Käärijä", Total: 42},
+	}
+
+	tests := []struct {
+		format              string
+		expectedContentType string
+		expectedBodyPrefix  string
+	}{
+		{format: util.ExportFormatCsv, expectedContentType: "text/csv; charset=utf-8", expectedBodyPrefix: "\ufeffrating_id,user_id,user_name"},
+		{format: util.ExportFormatJson, expectedContentType: "application/json", expectedBodyPrefix: `[{"rating_id":"r1"`},
+		{format: util.ExportFormatNdjson, expectedContentType: "application/x-ndjson", expectedBodyPrefix: `{"rating_id":"r1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			writer, err := util.NewExportWriter(c.Response(), tt.format, mapper.RatingExportColumns)
+			assert.NoError(t, err)
+
+			remaining := rows
+			err = streamExport(c.Response(), writer, tt.format, "competition-1", func() (util.ExportRow, error) {
+				if len(remaining) == 0 {
+					return nil, nil
+				}
+
+				row := remaining[0]
+				remaining = remaining[1:]
+				return row, nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "attachment; filename=\"competition-1."+tt.format+"\"", rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Contains(t, rec.Body.String(), "Käärijä")
+			assert.True(t, strings.HasPrefix(rec.Body.String(), tt.expectedBodyPrefix), rec.Body.String())
+		})
+	}
+}
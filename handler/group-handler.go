@@ -0,0 +1,513 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hyperremix/song-contest-rater-service/authz"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	inviteCodeAttempts   = 5
+	inviteCodeConstraint = "groups_invite_code_key"
+)
+
+type GroupHandler struct {
+	queries  *db.Queries
+	connPool *pgxpool.Pool
+}
+
+func NewGroupHandler(connPool *pgxpool.Pool) *GroupHandler {
+	return &GroupHandler{
+		queries:  db.New(connPool),
+		connPool: connPool,
+	}
+}
+
+func registerGroupRoutes(e *echo.Group, connPool *pgxpool.Pool) {
+	h := NewGroupHandler(connPool)
+
+	e.GET("/groups", h.listGroups)
+	e.GET("/groups/:id", h.getGroup)
+	e.POST("/groups", h.createGroup)
+	e.PUT("/groups/:id", h.updateGroup)
+	e.DELETE("/groups/:id", h.deleteGroup)
+	e.POST("/groups/join", h.joinGroup)
+	e.POST("/groups/:id/leave", h.leaveGroup)
+	e.POST("/groups/:id/invite-code", h.renewInviteCode)
+	e.GET("/groups/:id/competitions/:competitionId", h.getGroupCompetition)
+	e.GET("/groups/:id/stats/users", h.listGroupUserStats)
+	e.GET("/groups/:id/ratings/events", h.streamGroupRatings)
+}
+
+type groupCompetitionRequest struct {
+	Id            string `param:"id"`
+	CompetitionId string `param:"competitionId"`
+}
+
+func (h *GroupHandler) listGroups(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	groups, err := h.queries.ListGroupsByUserId(ctx, authUser.DbUser.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupListToResponse(groups)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) getGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupMember(ctx, h.queries, id); err != nil {
+		return err
+	}
+
+	group, err := h.queries.GetGroupById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.queries.ListUsersByGroupId(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) createGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.CreateGroupRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name must not be empty")
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	group, err := writeWithInviteCode(ctx, tx, func(queries *db.Queries, inviteCode string) (db.Group, error) {
+		return queries.InsertGroup(ctx, mapper.FromCreateRequestToInsertGroup(&request, authUser.DbUser.ID, inviteCode))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = queries.InsertGroupUser(ctx, db.InsertGroupUserParams{GroupID: group.ID, UserID: authUser.DbUser.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, []db.User{authUser.DbUser})
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusCreated, response)
+}
+
+func (h *GroupHandler) updateGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.UpdateGroupRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	paramId := echoCtx.Param("id")
+
+	if request.Id != paramId {
+		return echo.NewHTTPError(http.StatusBadRequest, "id in request does not match id in path")
+	}
+
+	updateParams, err := mapper.FromUpdateRequestToUpdateGroup(&request)
+	if err != nil {
+		return err
+	}
+
+	existingGroup, err := h.queries.GetGroupById(ctx, updateParams.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupOwner(existingGroup); err != nil {
+		return err
+	}
+
+	group, err := h.queries.UpdateGroup(ctx, updateParams)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, make([]db.User, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) deleteGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	existingGroup, err := h.queries.GetGroupById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsAdmin(); err != nil && authUser.CheckIsGroupOwner(existingGroup) != nil {
+		return err
+	}
+
+	group, err := h.queries.DeleteGroupById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, make([]db.User, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) joinGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.JoinGroupRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	group, err := h.queries.GetGroupByInviteCode(ctx, request.InviteCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "invalid invite code")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = h.queries.InsertGroupUser(ctx, db.InsertGroupUserParams{GroupID: group.ID, UserID: authUser.DbUser.ID})
+	if err != nil {
+		return err
+	}
+
+	users, err := h.queries.ListUsersByGroupId(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) leaveGroup(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	group, err := h.queries.GetGroupById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if authUser.CheckIsGroupOwner(group) == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "the owner cannot leave the group, delete it instead")
+	}
+
+	err = h.queries.DeleteGroupUser(ctx, db.DeleteGroupUserParams{GroupID: group.ID, UserID: authUser.DbUser.ID})
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (h *GroupHandler) renewInviteCode(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	existingGroup, err := h.queries.GetGroupById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupOwner(existingGroup); err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	group, err := writeWithInviteCode(ctx, tx, func(queries *db.Queries, inviteCode string) (db.Group, error) {
+		return queries.UpdateGroupInviteCode(ctx, db.UpdateGroupInviteCodeParams{ID: existingGroup.ID, InviteCode: inviteCode})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupToResponse(group, make([]db.User, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// writeWithInviteCode runs write with a new invite code until the code is
+// not taken by another group. Every attempt runs in a savepoint so that a
+// collision does not abort the surrounding transaction.
+func writeWithInviteCode(ctx context.Context, tx pgx.Tx, write func(queries *db.Queries, inviteCode string) (db.Group, error)) (db.Group, error) {
+	for attempt := 1; ; attempt++ {
+		inviteCode, err := util.NewInviteCode()
+		if err != nil {
+			return db.Group{}, err
+		}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return db.Group{}, err
+		}
+
+		group, err := write(db.New(savepoint), inviteCode)
+		if err == nil {
+			return group, savepoint.Commit(ctx)
+		}
+
+		if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+			return db.Group{}, rollbackErr
+		}
+
+		if !isInviteCodeCollision(err) || attempt == inviteCodeAttempts {
+			return db.Group{}, err
+		}
+	}
+}
+
+func isInviteCodeCollision(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == inviteCodeConstraint
+}
+
+func (h *GroupHandler) getGroupCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request groupCompetitionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	groupId, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupMember(ctx, h.queries, groupId); err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	ratings, err := h.queries.ListRatingsByCompetitionAndGroupId(ctx, db.ListRatingsByCompetitionAndGroupIdParams{CompetitionID: competition.ID, GroupID: groupId})
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.queries.ListUsersByCompetitionAndGroupId(ctx, db.ListUsersByCompetitionAndGroupIdParams{CompetitionID: competition.ID, GroupID: groupId})
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToCompetitionWithActsAndUsersResponse(competition, ratings, acts, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// listGroupUserStats computes the stats of the members from their ratings
+// and compares them with the average of the whole group.
+func (h *GroupHandler) listGroupUserStats(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	groupId, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupMember(ctx, h.queries, groupId); err != nil {
+		return err
+	}
+
+	usersStats, err := h.queries.ComputeUserStatsByGroupId(ctx, groupId)
+	if err != nil {
+		return err
+	}
+
+	groupStats, err := h.queries.ComputeGroupStatsByGroupId(ctx, groupId)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.queries.ListUsersByGroupId(ctx, groupId)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbGroupStatsToResponse(usersStats, groupStats, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *GroupHandler) streamGroupRatings(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request ratingEventsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	groupId, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := authUser.CheckIsGroupMember(ctx, h.queries, groupId); err != nil {
+		return err
+	}
+
+	brokerGroupId, err := mapper.FromDbToProtoId(groupId)
+	if err != nil {
+		return err
+	}
+
+	filter, err := fromRequestToRatingEventsFilter(request, brokerGroupId)
+	if err != nil {
+		return err
+	}
+
+	return streamEvents(echoCtx, authUser, filter.topic, func(afterId int64, limit int32) ([]db.RatingEvent, error) {
+		return h.queries.ListRatingEventsAfterIdByGroupId(ctx, db.ListRatingEventsAfterIdByGroupIdParams{
+			ID:            afterId,
+			GroupID:       groupId,
+			CompetitionID: filter.competitionId,
+			ActID:         filter.actId,
+			RowLimit:      limit,
+		})
+	})
+}
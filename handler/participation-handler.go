@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/db"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
@@ -28,6 +31,14 @@ func registerParticipationRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 	e.GET("/participations", h.listParticipations)
 	e.POST("/participations", h.createParticipation)
 	e.DELETE("/participations", h.deleteParticipation)
+	e.GET("/qualifications", h.listQualifications)
+	e.POST("/qualifications", h.createQualification)
+	e.DELETE("/qualifications", h.deleteQualification)
+	e.GET("/qualifications/status", h.getQualificationStatus)
+	e.PUT("/qualifications/status", h.updateQualificationStatus)
+	e.GET("/qualifications/predictions/me", h.getQualificationPrediction)
+	e.PUT("/qualifications/predictions/me", h.updateQualificationPrediction)
+	e.GET("/qualifications/predictions/scores", h.listQualificationPredictionScores)
 }
 
 func (h *ParticipationHandler) listParticipations(echoCtx echo.Context) error {
@@ -99,3 +110,348 @@ func (h *ParticipationHandler) deleteParticipation(echoCtx echo.Context) error {
 
 	return echoCtx.NoContent(http.StatusNoContent)
 }
+
+func (h *ParticipationHandler) listQualifications(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.ListQualificationsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToNullableDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	qualifications, err := h.queries.ListQualifications(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbQualificationListToResponse(qualifications)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *ParticipationHandler) createQualification(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.CreateQualificationRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	insertParams, err := mapper.FromCreateRequestToInsertQualification(&request)
+	if err != nil {
+		return err
+	}
+
+	if insertParams.CompetitionID == insertParams.TargetCompetitionID {
+		return echo.NewHTTPError(http.StatusBadRequest, "an act cannot qualify into the same competition")
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, insertParams.CompetitionID)
+	if err != nil {
+		return err
+	}
+
+	targetCompetition, err := h.queries.GetCompetitionById(ctx, insertParams.TargetCompetitionID)
+	if err != nil {
+		return err
+	}
+
+	if !targetCompetition.StartTime.Time.After(competition.StartTime.Time) {
+		return echo.NewHTTPError(http.StatusBadRequest, "target competition has to start after the competition")
+	}
+
+	if _, err := h.queries.GetCompetitionAct(ctx, db.GetCompetitionActParams{
+		CompetitionID: insertParams.CompetitionID,
+		ActID:         insertParams.ActID,
+	}); err != nil {
+		return err
+	}
+
+	qualification, err := h.queries.InsertQualification(ctx, insertParams)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbQualificationToResponse(qualification)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusCreated, response)
+}
+
+func (h *ParticipationHandler) deleteQualification(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.DeleteQualificationRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	deleteParams, err := mapper.FromDeleteRequestToDeleteQualification(&request)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.DeleteQualification(ctx, deleteParams); err != nil {
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusNoContent)
+}
+
+func (h *ParticipationHandler) getQualificationPrediction(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.GetQualificationPredictionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	predictions, err := h.queries.ListQualificationPredictionsByUserId(ctx, db.ListQualificationPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: competitionId,
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToQualificationPredictionResponse(competitionId, predictions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *ParticipationHandler) getQualificationStatus(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.GetQualificationStatusRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.queries.GetQualificationStatusByCompetitionId(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToQualificationStatusResponse(status)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updateQualificationStatus sets how many qualifiers a competition is
+// expected to have and whether its marked qualifiers are final. Qualifier
+// predictions are scored once either is reached.
+func (h *ParticipationHandler) updateQualificationStatus(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateQualificationStatusRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.ExpectedCount != nil && *request.ExpectedCount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected count has to be positive")
+	}
+
+	upsertParams, err := mapper.FromUpdateRequestToUpsertQualificationStatus(&request)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.GetCompetitionById(ctx, upsertParams.CompetitionID); err != nil {
+		return err
+	}
+
+	if err := h.queries.UpsertQualificationStatus(ctx, upsertParams); err != nil {
+		return err
+	}
+
+	status, err := h.queries.GetQualificationStatusByCompetitionId(ctx, upsertParams.CompetitionID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToQualificationStatusResponse(status)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updateQualificationPrediction replaces the predicted qualifiers of the
+// user. Predictions close when the competition starts, marking qualifiers
+// before then does not close them.
+func (h *ParticipationHandler) updateQualificationPrediction(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.UpdateQualificationPredictionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	actIds, err := mapper.FromUpdateRequestToActIds(&request)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForNoKeyUpdate(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	if !competition.StartTime.Time.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusConflict, "predictions are closed, the competition has already started")
+	}
+
+	competitionActIds, err := listCompetitionActIds(ctx, queries, competitionId)
+	if err != nil {
+		return err
+	}
+
+	for _, actId := range actIds {
+		if !competitionActIds[actId] {
+			return echo.NewHTTPError(http.StatusBadRequest, "act does not participate in the competition")
+		}
+	}
+
+	err = queries.DeleteQualificationPredictionsByUserId(ctx, db.DeleteQualificationPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: competitionId,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, actId := range actIds {
+		err = queries.InsertQualificationPrediction(ctx, db.InsertQualificationPredictionParams{
+			UserID:        authUser.DbUser.ID,
+			CompetitionID: competitionId,
+			ActID:         actId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	predictions, err := queries.ListQualificationPredictionsByUserId(ctx, db.ListQualificationPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: competitionId,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToQualificationPredictionResponse(competitionId, predictions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *ParticipationHandler) listQualificationPredictionScores(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.ListQualificationPredictionScoresRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToNullableDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	scores, err := h.queries.ListQualificationPredictionScores(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]pgtype.UUID, len(scores))
+	for i, score := range scores {
+		userIds[i] = score.UserID
+	}
+
+	users, err := h.queries.ListUsersByIds(ctx, userIds)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToQualificationPredictionScoresResponse(scores, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func listCompetitionActIds(ctx context.Context, queries *db.Queries, competitionId pgtype.UUID) (map[pgtype.UUID]bool, error) {
+	acts, err := queries.ListActsByCompetitionId(ctx, competitionId)
+	if err != nil {
+		return nil, err
+	}
+
+	actIds := make(map[pgtype.UUID]bool, len(acts))
+	for _, act := range acts {
+		actIds[act.ID] = true
+	}
+
+	return actIds, nil
+}
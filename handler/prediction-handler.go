@@ -0,0 +1,435 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/authz"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultComparisonLimit = 3
+	maxComparisonLimit     = 20
+)
+
+type PredictionHandler struct {
+	queries  *db.Queries
+	connPool *pgxpool.Pool
+}
+
+func NewPredictionHandler(connPool *pgxpool.Pool) *PredictionHandler {
+	return &PredictionHandler{
+		queries:  db.New(connPool),
+		connPool: connPool,
+	}
+}
+
+func registerPredictionRoutes(e *echo.Group, connPool *pgxpool.Pool) {
+	h := NewPredictionHandler(connPool)
+
+	e.GET("/competitions/:id/results", h.listResults)
+	e.PUT("/competitions/:id/results", h.updateResults)
+	e.GET("/competitions/:id/comparison", h.getComparison)
+	e.GET("/competitions/:id/predictions/me", h.getPrediction)
+	e.PUT("/competitions/:id/predictions/me", h.updatePrediction)
+	e.GET("/predictions/leaderboard", h.getLeaderboard)
+}
+
+func (h *PredictionHandler) listResults(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	// Unknown and deleted competitions are not found rather than empty.
+	if _, err := h.queries.GetCompetitionById(ctx, id); err != nil {
+		return err
+	}
+
+	results, err := h.queries.ListCompetitionResultsByCompetitionId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCompetitionResultListToResponse(id, results)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updateResults replaces the official results of a competition. They are
+// accepted as JSON, as a CSV body or as a CSV file in the file form field.
+func (h *PredictionHandler) updateResults(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateCompetitionResultsRequest
+	if err := bindResultsRequest(echoCtx, &request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	insertParams, err := mapper.FromResultsRequestToInsertCompetitionResults(id, request.Results)
+	if err != nil {
+		return err
+	}
+
+	placements := make([]int32, len(insertParams))
+	for i, params := range insertParams {
+		placements[i] = params.Placement
+	}
+
+	if err := util.ValidatePlacements(placements); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	competitionActIds, err := listCompetitionActIds(ctx, queries, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[pgtype.UUID]bool, len(insertParams))
+	for _, params := range insertParams {
+		if !competitionActIds[params.ActID] {
+			return echo.NewHTTPError(http.StatusBadRequest, "act does not participate in the competition")
+		}
+
+		if seen[params.ActID] {
+			return echo.NewHTTPError(http.StatusBadRequest, "act is placed more than once")
+		}
+		seen[params.ActID] = true
+	}
+
+	if err := queries.DeleteCompetitionResultsByCompetitionId(ctx, competition.ID); err != nil {
+		return err
+	}
+
+	for _, params := range insertParams {
+		if _, err := queries.InsertCompetitionResult(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	results, err := queries.ListCompetitionResultsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCompetitionResultListToResponse(competition.ID, results)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func bindResultsRequest(echoCtx echo.Context, request *mapper.UpdateCompetitionResultsRequest) error {
+	var csvFile io.Reader
+
+	contentType := echoCtx.Request().Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		csvFile = echoCtx.Request().Body
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm):
+		fileHeader, err := echoCtx.FormFile("file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		csvFile = file
+	default:
+		return echoCtx.Bind(request)
+	}
+
+	results, err := mapper.FromCsvToCompetitionResultRequests(csvFile)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	request.Id = echoCtx.Param("id")
+	request.Results = results
+
+	return nil
+}
+
+// getComparison compares the community ranking by the average rating
+// totals with the official results of a competition.
+func (h *PredictionHandler) getComparison(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.CompetitionComparisonRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	limit := int(request.Limit)
+	if limit <= 0 {
+		limit = defaultComparisonLimit
+	}
+	limit = min(limit, maxComparisonLimit)
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	results, err := h.queries.ListCompetitionResultsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	actStats, err := h.queries.ListActTotalStatsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	ratingAvgs, err := mapper.FromDbActStatsToRatingAvgsByAct(actStats)
+	if err != nil {
+		return err
+	}
+
+	placements, err := mapper.FromDbResultsToPlacementsByAct(results)
+	if err != nil {
+		return err
+	}
+
+	comparison, ok := util.CompareRankings(ratingAvgs, placements)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "at least two acts need official results and ratings to be compared")
+	}
+
+	response, err := mapper.FromComparisonToResponse(competition.ID, comparison, acts, limit)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *PredictionHandler) getPrediction(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	predictions, err := h.queries.ListRankingPredictionsByUserId(ctx, db.ListRankingPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: id,
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbRankingPredictionsToResponse(id, predictions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// updatePrediction replaces the predicted ranking of the user. Unlike
+// ratings, predictions are only accepted before the competition starts
+// and have to rank every act of the competition.
+func (h *PredictionHandler) updatePrediction(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.UpdateRankingPredictionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	insertParams, err := mapper.FromUpdateRequestToInsertRankingPredictions(authUser.DbUser.ID, id, &request)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForNoKeyUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !competition.StartTime.Time.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusConflict, "predictions are closed, the competition has already started")
+	}
+
+	competitionActIds, err := listCompetitionActIds(ctx, queries, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(insertParams) != len(competitionActIds) {
+		return echo.NewHTTPError(http.StatusBadRequest, "every act of the competition has to be ranked")
+	}
+
+	for _, params := range insertParams {
+		if !competitionActIds[params.ActID] {
+			return echo.NewHTTPError(http.StatusBadRequest, "act does not participate in the competition")
+		}
+	}
+
+	err = queries.DeleteRankingPredictionsByUserId(ctx, db.DeleteRankingPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: competition.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, params := range insertParams {
+		if err := queries.InsertRankingPrediction(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	predictions, err := queries.ListRankingPredictionsByUserId(ctx, db.ListRankingPredictionsByUserIdParams{
+		UserID:        authUser.DbUser.ID,
+		CompetitionID: competition.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbRankingPredictionsToResponse(competition.ID, predictions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *PredictionHandler) getLeaderboard(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request mapper.PredictionLeaderboardRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.Metric == "" {
+		request.Metric = util.PredictionMetricKendallTau
+	}
+
+	if request.Metric != util.PredictionMetricKendallTau && request.Metric != util.PredictionMetricPositionDistance {
+		return echo.NewHTTPError(http.StatusBadRequest, "metric must be kendallTau or positionDistance")
+	}
+
+	competitionId, err := mapper.FromProtoToNullableDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	predictions, err := h.queries.ListScoredRankingPredictions(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	pairs, err := mapper.FromDbScoredPredictionsToRankedPairs(predictions)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]pgtype.UUID, 0, len(pairs))
+	seen := make(map[pgtype.UUID]bool, len(pairs))
+	for _, prediction := range predictions {
+		if !seen[prediction.UserID] {
+			seen[prediction.UserID] = true
+			userIds = append(userIds, prediction.UserID)
+		}
+	}
+
+	users, err := h.queries.ListUsersByIds(ctx, userIds)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromPredictionScoresToLeaderboardResponse(util.ScorePredictions(pairs, request.Metric), users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
@@ -1,15 +1,25 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
 	"time"
 
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/live"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
 	"github.com/hyperremix/song-contest-rater-service/sse"
 	"github.com/hyperremix/song-contest-rater-service/stat"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/hyperremix/song-contest-rater-service/voting"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
@@ -36,16 +46,38 @@ func registerRatingRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 	e.GET("/users/:id/ratings", h.listUserRatings)
 	e.GET("/acts/:id/ratings", h.listActRatings)
 	e.GET("/ratings/:id", h.getRating)
+	e.GET("/ratings/:id/scores", h.getRatingScores)
 	e.POST("/ratings", h.createRating)
 	e.PUT("/ratings/:id", h.updateRating)
 	e.DELETE("/ratings/:id", h.deleteRating)
+	e.POST("/ratings/:id/restore", h.restoreRating)
 	e.GET("/ratings/events", h.streamRatings)
 }
 
 func (h *RatingHandler) listRatings(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 
-	ratings, err := h.queries.ListRatings(ctx)
+	var request mapper.ListRatingsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	page, err := util.NewPage(request.Limit, request.Cursor, request.Sort, "-createdAt", "createdAt", "total")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	params, err := mapper.FromListRequestToListRatingsPage(request, page)
+	if err != nil {
+		return err
+	}
+
+	ratings, err := h.queries.ListRatingsPage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	ratings, nextCursor, err := util.Paginate(page, ratings, mapper.FromDbRatingToPosition(page.SortBy))
 	if err != nil {
 		return err
 	}
@@ -55,7 +87,7 @@ func (h *RatingHandler) listRatings(echoCtx echo.Context) error {
 		return err
 	}
 
-	return echoCtx.JSON(http.StatusOK, response)
+	return echoCtx.JSON(http.StatusOK, &mapper.ListRatingsPageResponse{ListRatingsResponse: response, NextCursor: nextCursor})
 }
 
 func (h *RatingHandler) listUserRatings(echoCtx echo.Context) error {
@@ -146,21 +178,78 @@ func (h *RatingHandler) getRating(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+func (h *RatingHandler) getRatingScores(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	rating, err := h.queries.GetRatingById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	scores, err := h.queries.ListRatingScoresByRatingId(ctx, rating.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToRatingScoresResponse(rating, scores)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// createRating inserts the rating, updates the stats and appends the rating
+// event to the event log in a single transaction. The event is broadcast
+// only after the transaction was committed.
 func (h *RatingHandler) createRating(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
 
-	var request pb.CreateRatingRequest
+	var request mapper.CreateRatingRequest
 	if err := echoCtx.Bind(&request); err != nil {
 		return err
 	}
 
-	insertRatingParams, err := mapper.FromCreateRequestToInsertRating(&request, authUser.UserID)
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForShare(ctx, competitionId)
 	if err != nil {
 		return err
 	}
 
-	competition, err := h.queries.GetCompetitionById(ctx, insertRatingParams.CompetitionID)
+	categories, err := queries.ListCompetitionCategoriesByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	scores, err := mapper.FromCreateRequestToScores(&request, categories)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	insertRatingParams, err := mapper.FromCreateRequestToInsertRating(&request, authUser.UserID, scores, categories)
 	if err != nil {
 		return err
 	}
@@ -169,28 +258,52 @@ func (h *RatingHandler) createRating(echoCtx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "competition has not started yet")
 	}
 
-	rating, err := h.queries.InsertRating(ctx, insertRatingParams)
+	if err := voting.CheckIsOpen(competition, time.Now()); err != nil {
+		return err
+	}
+
+	if competition.PerformedActsOnly {
+		competitionAct, err := queries.GetCompetitionAct(ctx, db.GetCompetitionActParams{
+			CompetitionID: competition.ID,
+			ActID:         insertRatingParams.ActID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !live.HasPerformed(live.FromCompetition(competition), competitionAct.Order) {
+			return echo.NewHTTPError(http.StatusBadRequest, "act has not performed yet")
+		}
+	}
+
+	rating, err := queries.InsertRating(ctx, insertRatingParams)
 	if err != nil {
 		return err
 	}
 
+	if err := insertRatingScores(ctx, queries, rating.ID, scores, categories); err != nil {
+		return err
+	}
+
 	response, err := mapper.FromDbRatingToResponse(rating, &authUser.DbUser)
 	if err != nil {
 		return err
 	}
 
-	event, err := sse.NewEvent(sse.EventOptions{
-		ID:    response.Id,
-		Event: "createRating",
-		Data:  response,
-		Retry: 10000,
-	})
+	if err := h.statService.AddRatingToStats(ctx, queries, response); err != nil {
+		return err
+	}
+
+	ratingEvent, err := insertRatingEvent(ctx, queries, authUser.DbUser.ID, "createRating", response)
 	if err != nil {
 		return err
 	}
 
-	broker.BroadcastEvent(authUser.UserID, event)
-	h.statService.AddRatingToStats(ctx, response)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	h.publishRatingEvent(ctx, authUser.UserID, ratingEvent, response)
 	return echoCtx.JSON(http.StatusCreated, response)
 }
 
@@ -198,7 +311,7 @@ func (h *RatingHandler) updateRating(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
 
-	var request pb.UpdateRatingRequest
+	var request mapper.UpdateRatingRequest
 	if err := echoCtx.Bind(&request); err != nil {
 		return err
 	}
@@ -213,7 +326,15 @@ func (h *RatingHandler) updateRating(echoCtx echo.Context) error {
 		return err
 	}
 
-	existingRating, err := h.queries.GetRatingById(ctx, id)
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	existingRating, err := queries.GetRatingByIdForUpdate(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -222,13 +343,45 @@ func (h *RatingHandler) updateRating(echoCtx echo.Context) error {
 		return err
 	}
 
-	updateRatingParams, err := mapper.FromUpdateRequestToUpdateRating(&request)
+	if err := checkIsVotingOpen(ctx, queries, existingRating.CompetitionID); err != nil {
+		return err
+	}
+
+	oldResponse, err := mapper.FromDbRatingToResponse(existingRating, &authUser.DbUser)
+	if err != nil {
+		return err
+	}
+
+	categories, err := queries.ListCompetitionCategoriesByCompetitionId(ctx, existingRating.CompetitionID)
 	if err != nil {
 		return err
 	}
 
-	rating, err := h.queries.UpdateRating(ctx, updateRatingParams)
+	scores, err := mapper.FromUpdateRequestToScores(&request, categories)
 	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	updateRatingParams, err := mapper.FromUpdateRequestToUpdateRating(&request, scores, categories)
+	if err != nil {
+		return err
+	}
+
+	oldScores, err := queries.ListRatingScoresByRatingId(ctx, existingRating.ID)
+	if err != nil {
+		return err
+	}
+
+	rating, err := queries.UpdateRating(ctx, updateRatingParams)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteRatingScoresByRatingId(ctx, rating.ID); err != nil {
+		return err
+	}
+
+	if err := insertRatingScores(ctx, queries, rating.ID, scores, categories); err != nil {
 		return err
 	}
 
@@ -237,18 +390,20 @@ func (h *RatingHandler) updateRating(echoCtx echo.Context) error {
 		return err
 	}
 
-	event, err := sse.NewEvent(sse.EventOptions{
-		ID:    response.Id,
-		Event: "updateRating",
-		Data:  response,
-		Retry: 10000,
-	})
+	if err := h.statService.UpdateRatingInStats(ctx, queries, oldResponse, oldScores, response); err != nil {
+		return err
+	}
+
+	ratingEvent, err := insertRatingEvent(ctx, queries, authUser.DbUser.ID, "updateRating", response)
 	if err != nil {
 		return err
 	}
 
-	broker.BroadcastEvent(authUser.UserID, event)
-	h.statService.UpdateRatingInStats(ctx, response)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	h.publishRatingEvent(ctx, authUser.UserID, ratingEvent, response)
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
@@ -266,7 +421,15 @@ func (h *RatingHandler) deleteRating(echoCtx echo.Context) error {
 		return err
 	}
 
-	existingRating, err := h.queries.GetRatingById(ctx, id)
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	existingRating, err := queries.GetRatingByIdForUpdate(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -275,66 +438,338 @@ func (h *RatingHandler) deleteRating(echoCtx echo.Context) error {
 		return err
 	}
 
-	rating, err := h.queries.DeleteRatingById(ctx, id)
+	if err := checkIsVotingOpen(ctx, queries, existingRating.CompetitionID); err != nil {
+		return err
+	}
+
+	rating, err := queries.SoftDeleteRatingById(ctx, db.SoftDeleteRatingByIdParams{
+		ID:        id,
+		DeletedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
 	if err != nil {
 		return err
 	}
 
-	response, err := mapper.FromDbRatingToResponse(rating, nil)
+	response, err := mapper.FromDbRatingToResponse(rating, &authUser.DbUser)
 	if err != nil {
 		return err
 	}
 
-	event, err := sse.NewEvent(sse.EventOptions{
-		ID:    response.Id,
-		Event: "deleteRating",
-		Data:  response,
-		Retry: 10000,
-	})
+	if err := h.statService.RemoveRatingFromStats(ctx, queries, response); err != nil {
+		return err
+	}
+
+	ratingEvent, err := insertRatingEvent(ctx, queries, authUser.DbUser.ID, "deleteRating", response)
 	if err != nil {
 		return err
 	}
 
-	broker.BroadcastEvent(authUser.UserID, event)
-	h.statService.RemoveRatingFromStats(ctx, response)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	h.publishRatingEvent(ctx, authUser.UserID, ratingEvent, response)
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
-func (h *RatingHandler) streamRatings(echoCtx echo.Context) error {
-	echoCtx.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
-	echoCtx.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
-	echoCtx.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+// restoreRating restores a deleted rating as long as its competition and
+// act exist, voting in the competition is open and its author has not
+// rated the act again since. The restore is an event of the author's
+// rating like any other rating write.
+func (h *RatingHandler) restoreRating(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := h.queries.WithTx(tx)
+
+	rating, err := queries.GetDeletedRatingByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := queries.GetCompetitionByIdForShare(ctx, rating.CompetitionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusConflict, "the competition of the rating is deleted")
+	} else if err != nil {
+		return err
+	}
+
+	if err := voting.CheckIsOpen(competition, time.Now()); err != nil {
+		return err
+	}
+
+	if _, err := queries.GetActById(ctx, rating.ActID); errors.Is(err, pgx.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusConflict, "the act of the rating is deleted")
+	} else if err != nil {
+		return err
+	}
+
+	responses, err := restoreRatings(ctx, queries, h.statService, []db.Rating{rating})
+	if err != nil {
+		return err
+	}
+
+	ratingEvent, err := insertRatingEvent(ctx, queries, rating.UserID, "restoreRating", responses[0])
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	h.publishRatingEvent(ctx, authUser.UserID, ratingEvent, responses[0])
+	return echoCtx.JSON(http.StatusOK, responses[0])
+}
+
+// lockRatingCompetitions locks the competitions of the ratings like any
+// other rating write does, so that they cannot be changed until the
+// ratings are written, and freezes the scoreboards of those that closed
+// already. The competitions are locked in a fixed order to avoid deadlocks
+// with changes of the competitions.
+func lockRatingCompetitions(ctx context.Context, queries *db.Queries, ratings []db.Rating) error {
+	competitionIds := make([]pgtype.UUID, 0)
+	seen := make(map[pgtype.UUID]bool)
+	for _, rating := range ratings {
+		if !seen[rating.CompetitionID] {
+			seen[rating.CompetitionID] = true
+			competitionIds = append(competitionIds, rating.CompetitionID)
+		}
+	}
+
+	sort.Slice(competitionIds, func(i, j int) bool {
+		return bytes.Compare(competitionIds[i].Bytes[:], competitionIds[j].Bytes[:]) < 0
+	})
+
+	for _, competitionId := range competitionIds {
+		competition, err := queries.GetCompetitionByIdForShare(ctx, competitionId)
+		if err != nil {
+			return err
+		}
+
+		if err := voting.Freeze(ctx, queries, competition, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// softDeleteRatings marks the ratings as deleted at the same time as the
+// competition or act they are deleted with, which is how they are found
+// again on restore, and removes them from the stats.
+func softDeleteRatings(ctx context.Context, queries *db.Queries, statService *stat.Service, ratings []db.Rating, deletedAt pgtype.Timestamptz) error {
+	users, err := listRatingUsers(ctx, queries, ratings)
+	if err != nil {
+		return err
+	}
+
+	for _, rating := range ratings {
+		deletedRating, err := queries.SoftDeleteRatingById(ctx, db.SoftDeleteRatingByIdParams{
+			ID:        rating.ID,
+			DeletedAt: deletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		response, err := mapper.FromDbRatingToResponse(deletedRating, users[rating.UserID])
+		if err != nil {
+			return err
+		}
+
+		if err := statService.RemoveRatingFromStats(ctx, queries, response); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreRatings restores the deleted ratings and adds them to the stats
+// again.
+func restoreRatings(ctx context.Context, queries *db.Queries, statService *stat.Service, ratings []db.Rating) ([]*pb.RatingResponse, error) {
+	users, err := listRatingUsers(ctx, queries, ratings)
+	if err != nil {
+		return nil, err
+	}
 
-	ticker := time.NewTicker(15 * time.Second)
-	ch := make(chan sse.Event)
-	broker.AddUserChan(authUser.UserID, ch)
-
-	log := zerolog.Ctx(echoCtx.Request().Context())
-
-	defer ticker.Stop()
-	defer broker.RemoveUserChan(authUser.UserID, ch)
-	for {
-		select {
-		case <-echoCtx.Request().Context().Done():
-			return nil
-		case e := <-ch:
-			if err := e.MarshalTo(echoCtx.Response().Writer); err != nil {
-				return err
-			}
-			echoCtx.Response().Flush()
-		case <-ticker.C:
-			event, err := sse.NewEvent(sse.EventOptions{
-				ID:    "system",
-				Event: "ping",
-				Retry: 10000,
-				Data:  "keep-alive",
-			})
-			if err != nil {
-				log.Error().Err(err).Msg("error creating ping event")
-				continue
-			}
-			broker.BroadcastEvent("system", event)
+	responses := make([]*pb.RatingResponse, len(ratings))
+	for i, rating := range ratings {
+		restoredRating, err := queries.RestoreRatingById(ctx, rating.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		responses[i], err = mapper.FromDbRatingToResponse(restoredRating, users[rating.UserID])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := statService.AddRatingToStats(ctx, queries, responses[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return responses, nil
+}
+
+func listRatingUsers(ctx context.Context, queries *db.Queries, ratings []db.Rating) (map[pgtype.UUID]*db.User, error) {
+	userIds := make([]pgtype.UUID, len(ratings))
+	for i, rating := range ratings {
+		userIds[i] = rating.UserID
+	}
+
+	users, err := queries.ListUsersByIds(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	usersById := make(map[pgtype.UUID]*db.User, len(users))
+	for i := range users {
+		usersById[users[i].ID] = &users[i]
+	}
+
+	return usersById, nil
+}
+
+func insertRatingScores(ctx context.Context, queries *db.Queries, ratingId pgtype.UUID, scores map[string]int32, categories []db.CompetitionCategory) error {
+	for _, params := range mapper.FromScoresToInsertRatingScores(ratingId, scores, categories) {
+		if err := queries.InsertRatingScore(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkIsVotingOpen locks the competition until the rating write is
+// committed, so that it cannot be locked or have its categories changed.
+// The lock is shared, rating writes of the same competition only wait for
+// each other on the stats rows they update.
+func checkIsVotingOpen(ctx context.Context, queries *db.Queries, competitionId pgtype.UUID) error {
+	competition, err := queries.GetCompetitionByIdForShare(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	return voting.CheckIsOpen(competition, time.Now())
+}
+
+// insertRatingEvent appends the event to the rating event log, which acts
+// as the outbox of the rating write it is part of. The ids of the events
+// are assigned on insert, so the log is locked until the write commits.
+// Otherwise an event could commit after one with a greater id and be
+// missed by clients that replay the events after that id. It has to be
+// the last statement of the write to keep the lock short.
+func insertRatingEvent(ctx context.Context, queries *db.Queries, authorId pgtype.UUID, eventName string, response *pb.RatingResponse) (db.RatingEvent, error) {
+	competitionId, err := mapper.FromProtoToDbId(response.CompetitionId)
+	if err != nil {
+		return db.RatingEvent{}, err
+	}
+
+	actId, err := mapper.FromProtoToDbId(response.ActId)
+	if err != nil {
+		return db.RatingEvent{}, err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return db.RatingEvent{}, err
+	}
+
+	if err := queries.LockRatingEventLog(ctx); err != nil {
+		return db.RatingEvent{}, err
+	}
+
+	return queries.InsertRatingEvent(ctx, db.InsertRatingEventParams{
+		Event:         eventName,
+		UserID:        authorId,
+		CompetitionID: competitionId,
+		ActID:         actId,
+		Data:          data,
+	})
+}
+
+// publishRatingEvent publishes a committed rating event to the subscribers
+// of the rating's competition and act and to the subscribers of every group
+// the author is a member of. The event is not sent back to the user who
+// wrote the rating, which is the author unless an admin restored it. The
+// rating has already been written, so a failed broadcast is only logged,
+// clients that missed the event replay it from the event log when they
+// reconnect.
+func (h *RatingHandler) publishRatingEvent(ctx context.Context, sourceUserId string, ratingEvent db.RatingEvent, response *pb.RatingResponse) {
+	log := zerolog.Ctx(ctx)
+
+	event, err := newRatingEvent(ratingEvent)
+	if err != nil {
+		log.Error().Err(err).Msg("error creating rating event")
+		return
+	}
+
+	topic := sse.Topic{CompetitionId: response.CompetitionId, ActId: response.ActId}
+	if err := publisher.Publish(ctx, sse.Message{Topic: topic, SourceUserId: sourceUserId, Event: event}); err != nil {
+		log.Error().Err(err).Msg("error publishing rating event")
+	}
+
+	groups, err := h.queries.ListGroupsByUserId(ctx, ratingEvent.UserID)
+	if err != nil {
+		log.Error().Err(err).Msg("error listing groups of rating event")
+		return
+	}
+
+	for _, group := range groups {
+		topic.GroupId, err = mapper.FromDbToProtoId(group.ID)
+		if err != nil {
+			log.Error().Err(err).Msg("error creating rating event topic")
+			continue
+		}
+
+		if err := publisher.Publish(ctx, sse.Message{Topic: topic, SourceUserId: sourceUserId, Event: event}); err != nil {
+			log.Error().Err(err).Msg("error publishing rating event")
 		}
 	}
 }
+
+func (h *RatingHandler) streamRatings(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request ratingEventsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	filter, err := fromRequestToRatingEventsFilter(request, "")
+	if err != nil {
+		return err
+	}
+
+	return streamEvents(echoCtx, authUser, filter.topic, func(afterId int64, limit int32) ([]db.RatingEvent, error) {
+		return h.queries.ListRatingEventsAfterId(ctx, db.ListRatingEventsAfterIdParams{
+			ID:            afterId,
+			CompetitionID: filter.competitionId,
+			ActID:         filter.actId,
+			RowLimit:      limit,
+		})
+	})
+}
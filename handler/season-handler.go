@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/hyperremix/song-contest-rater-service/authz"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+type SeasonHandler struct {
+	queries  *db.Queries
+	connPool *pgxpool.Pool
+}
+
+func NewSeasonHandler(connPool *pgxpool.Pool) *SeasonHandler {
+	return &SeasonHandler{
+		queries:  db.New(connPool),
+		connPool: connPool,
+	}
+}
+
+func registerSeasonRoutes(e *echo.Group, connPool *pgxpool.Pool) {
+	h := NewSeasonHandler(connPool)
+
+	e.GET("/seasons", h.listSeasons)
+	e.GET("/seasons/:id", h.getSeason)
+	e.POST("/seasons", h.createSeason)
+	e.PUT("/seasons/:id", h.updateSeason)
+	e.DELETE("/seasons/:id", h.deleteSeason)
+	e.GET("/seasons/:id/competitions", h.listSeasonCompetitions)
+	e.PUT("/seasons/:id/competitions/:competitionId", h.addSeasonCompetition)
+	e.DELETE("/seasons/:id/competitions/:competitionId", h.removeSeasonCompetition)
+	e.GET("/seasons/:id/stats/users", h.listSeasonUserStats)
+	e.GET("/seasons/:id/progression", h.getSeasonProgression)
+}
+
+func (h *SeasonHandler) listSeasons(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	seasons, err := h.queries.ListSeasons(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonListToResponse(seasons)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) getSeason(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	season, err := h.queries.GetSeasonById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	competitions, err := h.queries.ListCompetitionsBySeasonId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonToResponse(season, competitions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) createSeason(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.CreateSeasonRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	if err := validateSeason(request.Name, request.Year); err != nil {
+		return err
+	}
+
+	season, err := h.queries.InsertSeason(ctx, mapper.FromCreateRequestToInsertSeason(&request))
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonToResponse(season, make([]db.Competition, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusCreated, response)
+}
+
+func (h *SeasonHandler) updateSeason(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.UpdateSeasonRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	paramId := echoCtx.Param("id")
+
+	if request.Id != paramId {
+		return echo.NewHTTPError(http.StatusBadRequest, "id in request does not match id in path")
+	}
+
+	if err := validateSeason(request.Name, request.Year); err != nil {
+		return err
+	}
+
+	updateParams, err := mapper.FromUpdateRequestToUpdateSeason(&request)
+	if err != nil {
+		return err
+	}
+
+	season, err := h.queries.UpdateSeason(ctx, updateParams)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonToResponse(season, make([]db.Competition, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) deleteSeason(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	season, err := h.queries.DeleteSeasonById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonToResponse(season, make([]db.Competition, 0))
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) listSeasonCompetitions(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.GetSeasonById(ctx, id); err != nil {
+		return err
+	}
+
+	competitions, err := h.queries.ListCompetitionsBySeasonId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbCompetitionListToResponse(competitions)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) addSeasonCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.SeasonCompetitionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.GetSeasonById(ctx, id); err != nil {
+		return err
+	}
+
+	// Deleted competitions are not updated and therefore not found.
+	competition, err := h.queries.UpdateCompetitionSeasonId(ctx, db.UpdateCompetitionSeasonIdParams{ID: competitionId, SeasonID: id})
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToSeasonCompetitionResponse(competition)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) removeSeasonCompetition(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.SeasonCompetitionRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competitionId, err := mapper.FromProtoToDbId(request.CompetitionId)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, competitionId)
+	if err != nil {
+		return err
+	}
+
+	if competition.SeasonID != id {
+		return pgx.ErrNoRows
+	}
+
+	competition, err = h.queries.UpdateCompetitionSeasonId(ctx, db.UpdateCompetitionSeasonIdParams{ID: competitionId, SeasonID: pgtype.UUID{}})
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToSeasonCompetitionResponse(competition)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) listSeasonUserStats(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.GetSeasonById(ctx, id); err != nil {
+		return err
+	}
+
+	usersStats, err := h.queries.ComputeUserStatsBySeasonId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	seasonStats, err := h.queries.ComputeGlobalStatsBySeasonId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]pgtype.UUID, len(usersStats))
+	for i, stats := range usersStats {
+		userIds[i] = stats.UserID
+	}
+
+	users, err := h.queries.ListUsersByIds(ctx, userIds)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSeasonStatsToResponse(usersStats, seasonStats, users)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *SeasonHandler) getSeasonProgression(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.queries.GetSeasonById(ctx, id); err != nil {
+		return err
+	}
+
+	acts, err := h.queries.ListSemiFinalActsBySeasonId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbSemiFinalActsToProgressionResponse(id, acts)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func validateSeason(name string, year int32) error {
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name must not be empty")
+	}
+
+	if year < 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "year must be positive")
+	}
+
+	return nil
+}
@@ -8,19 +8,30 @@ import (
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/db"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/hyperremix/song-contest-rater-service/stat"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	defaultSimilarUsersLimit      = 5
+	maxSimilarUsersLimit          = 50
+	defaultSimilarUsersMinOverlap = 3
+)
+
 type StatHandler struct {
-	queries *db.Queries
-	pool    *pgxpool.Pool
+	queries     *db.Queries
+	pool        *pgxpool.Pool
+	statService *stat.Service
 }
 
 func NewStatHandler(pool *pgxpool.Pool) *StatHandler {
 	return &StatHandler{
-		queries: db.New(pool),
-		pool:    pool,
+		queries:     db.New(pool),
+		pool:        pool,
+		statService: stat.NewService(pool),
 	}
 }
 
@@ -29,13 +40,39 @@ func registerStatRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 
 	e.GET("/stats/users", h.listUserStats)
 	e.GET("/stats/users/me", h.getUserStats)
+	e.GET("/stats/users/me/similar", h.listSimilarUsers)
+	e.GET("/stats/users/:id/history", h.getUserStatsHistory)
+	e.GET("/stats/users/:id/profile", h.getCriticProfile)
 	e.GET("/stats/global", h.getGlobalStats)
+	e.GET("/stats/acts/:id", h.getActStats)
+	e.GET("/stats/competitions/:id", h.getCompetitionStats)
+	e.POST("/stats/recompute", h.recomputeStats)
 }
 
 func (h *StatHandler) listUserStats(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 
-	usersStats, err := h.queries.ListUserStats(ctx)
+	var request mapper.ListUserStatsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	page, err := util.NewPage(request.Limit, request.Cursor, request.Sort, "-ratingAvg", "ratingAvg", "ratingCount")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	params, err := mapper.FromListRequestToListUserStatsPage(request, page)
+	if err != nil {
+		return err
+	}
+
+	usersStats, err := h.queries.ListUserStatsPage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	usersStats, nextCursor, err := util.Paginate(page, usersStats, mapper.FromDbUserStatsToPosition(page.SortBy))
 	if err != nil {
 		return err
 	}
@@ -45,7 +82,7 @@ func (h *StatHandler) listUserStats(echoCtx echo.Context) error {
 		return err
 	}
 
-	users, err := h.queries.ListUsers(ctx)
+	users, err := h.queries.ListUsersByIds(ctx, mapper.FromDbUserStatsToUserIds(usersStats))
 	if err != nil {
 		return err
 	}
@@ -55,7 +92,7 @@ func (h *StatHandler) listUserStats(echoCtx echo.Context) error {
 		return err
 	}
 
-	return echoCtx.JSON(http.StatusOK, response)
+	return echoCtx.JSON(http.StatusOK, &mapper.ListUserStatsPageResponse{ListUserStatsResponse: response, NextCursor: nextCursor})
 }
 
 func (h *StatHandler) getUserStats(echoCtx echo.Context) error {
@@ -87,6 +124,143 @@ func (h *StatHandler) getUserStats(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// listSimilarUsers correlates the caller's ratings with the ratings of every
+// other user over the acts both of them rated.
+func (h *StatHandler) listSimilarUsers(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	var request mapper.ListSimilarUsersRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	limit := int(request.Limit)
+	if limit <= 0 {
+		limit = defaultSimilarUsersLimit
+	}
+	limit = min(limit, maxSimilarUsersLimit)
+
+	minOverlap := int(request.MinOverlap)
+	if minOverlap <= 0 {
+		minOverlap = defaultSimilarUsersMinOverlap
+	}
+
+	ratings, err := h.queries.ListRatingsByUserId(ctx, authUser.DbUser.ID)
+	if err != nil {
+		return err
+	}
+
+	overlappingRatings, err := h.queries.ListOverlappingRatingsByUserId(ctx, authUser.DbUser.ID)
+	if err != nil {
+		return err
+	}
+
+	othersTotals, err := mapper.FromDbRatingsToTotalsByUserAndAct(overlappingRatings)
+	if err != nil {
+		return err
+	}
+
+	similarities := util.Similarities(mapper.FromDbRatingsToTotalsByAct(ratings), othersTotals, minOverlap)
+
+	users, err := h.queries.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromSimilaritiesToResponse(similarities, users, limit)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *StatHandler) getUserStatsHistory(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.queries.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := h.queries.ListUserStatsSnapshotsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbUserStatsSnapshotsToHistoryResponse(snapshots, user)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *StatHandler) getCriticProfile(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.queries.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	userStats := mapper.EmptyUserStatsResponse()
+	dbUserStats, err := h.queries.GetStatsByUserId(ctx, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		globalStats, err := h.queries.GetGlobalStats(ctx)
+		if err != nil {
+			return err
+		}
+
+		userStats, err = mapper.FromDbUserStatsToResponse(dbUserStats, globalStats, &user)
+		if err != nil {
+			return err
+		}
+	}
+
+	userCategoryStats, err := h.queries.ListUserCategoryStatsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	globalCategoryStats, err := h.queries.ListGlobalCategoryStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToCriticProfileResponse(userStats, user, userCategoryStats, globalCategoryStats)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
 func (h *StatHandler) getGlobalStats(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 
@@ -105,3 +279,92 @@ func (h *StatHandler) getGlobalStats(echoCtx echo.Context) error {
 
 	return echoCtx.JSON(http.StatusOK, response)
 }
+
+// recomputeStats rebuilds the stats from the ratings and reports the
+// drift of the stored stats. With dryRun=true only the drift is reported.
+func (h *StatHandler) recomputeStats(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	var request mapper.RecomputeStatsRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	report, err := h.statService.Recompute(ctx, request.DryRun)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, report)
+}
+
+func (h *StatHandler) getActStats(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	act, err := h.queries.GetActById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	actStats, err := h.queries.ListActStatsByActId(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToActStatsResponse(act.ID, actStats)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+func (h *StatHandler) getCompetitionStats(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var request singleObjectRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	id, err := mapper.FromProtoToDbId(request.Id)
+	if err != nil {
+		return err
+	}
+
+	competition, err := h.queries.GetCompetitionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	competitionStats, err := h.queries.ListCompetitionStatsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	actStats, err := h.queries.ListActTotalStatsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbToCompetitionStatsResponse(competition.ID, competitionStats, actStats)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
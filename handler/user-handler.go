@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -13,22 +14,28 @@ import (
 	"github.com/hyperremix/song-contest-rater-service/db"
 	"github.com/hyperremix/song-contest-rater-service/mapper"
 	"github.com/hyperremix/song-contest-rater-service/s3"
+	"github.com/hyperremix/song-contest-rater-service/stat"
+	"github.com/hyperremix/song-contest-rater-service/util"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
 )
 
 type UserHandler struct {
-	queries  *db.Queries
-	connPool *pgxpool.Pool
-	s3Client *s3.Client
+	queries     *db.Queries
+	connPool    *pgxpool.Pool
+	s3Client    *s3.Client
+	statService *stat.Service
 }
 
 func NewUserHandler(connPool *pgxpool.Pool) *UserHandler {
 	return &UserHandler{
-		queries:  db.New(connPool),
-		connPool: connPool,
-		s3Client: s3.New(),
+		queries:     db.New(connPool),
+		connPool:    connPool,
+		s3Client:    s3.New(),
+		statService: stat.NewService(connPool),
 	}
 }
 
@@ -38,6 +45,8 @@ func registerUserRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 	e.GET("/users", h.listUsers)
 	e.GET("/users/:id", h.getUser)
 	e.GET("/users/me", h.getAuthUser)
+	e.GET("/users/me/export", h.exportAuthUser)
+	e.GET("/users/erasures", h.listUserErasures)
 	e.POST("/users", h.createUser)
 	e.PUT("/users/:id", h.updateUser)
 	e.DELETE("/users/:id", h.deleteUser)
@@ -47,7 +56,27 @@ func registerUserRoutes(e *echo.Group, connPool *pgxpool.Pool) {
 func (h *UserHandler) listUsers(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 
-	users, err := h.queries.ListUsers(ctx)
+	var request mapper.ListUsersRequest
+	if err := echoCtx.Bind(&request); err != nil {
+		return err
+	}
+
+	page, err := util.NewPage(request.Limit, request.Cursor, request.Sort, "lastname", "firstname", "lastname", "createdAt")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	params, err := mapper.FromListRequestToListUsersPage(request, page)
+	if err != nil {
+		return err
+	}
+
+	users, err := h.queries.ListUsersPage(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	users, nextCursor, err := util.Paginate(page, users, mapper.FromDbUserToPosition(page.SortBy))
 	if err != nil {
 		return err
 	}
@@ -57,7 +86,7 @@ func (h *UserHandler) listUsers(echoCtx echo.Context) error {
 		return err
 	}
 
-	return echoCtx.JSON(http.StatusOK, response)
+	return echoCtx.JSON(http.StatusOK, &mapper.ListUsersPageResponse{ListUsersResponse: response, NextCursor: nextCursor})
 }
 
 func (h *UserHandler) getUser(echoCtx echo.Context) error {
@@ -171,6 +200,12 @@ func (h *UserHandler) updateUser(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// deleteUser erases the user and all of their personal data. The ratings
+// of the user are removed from the competitions and the stats, the groups
+// they own are handed to another member or deleted if they have none.
+// The database is erased in a single transaction, the profile pictures
+// and the Clerk metadata afterwards. Every step is recorded in the
+// erasure audit trail.
 func (h *UserHandler) deleteUser(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 
@@ -189,12 +224,144 @@ func (h *UserHandler) deleteUser(echoCtx echo.Context) error {
 		return err
 	}
 
-	user, err := h.queries.DeleteUserById(ctx, id)
+	tx, err := h.connPool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	response, err := mapper.FromDbUserToResponse(user)
+	queries := h.queries.WithTx(tx)
+
+	user, err := queries.GetUserByIdForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	erasure, err := h.eraseUserData(ctx, queries, user, authUser.DbUser.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	userId, err := mapper.FromDbToProtoId(user.ID)
+	if err != nil {
+		return err
+	}
+
+	deletedImages, err := h.s3Client.DeleteProfilePictures(ctx, userId)
+	clerkUnlinked := false
+	if err == nil {
+		err = authz.UnlinkClerkUser(ctx, user.Sub)
+		clerkUnlinked = err == nil
+	}
+
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to erase user %s outside of the database", userId)
+	}
+
+	erasure, err = h.queries.CompleteUserErasure(ctx, mapper.FromErasureResultToCompleteUserErasure(erasure.ID, deletedImages, clerkUnlinked, err))
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbUserErasureToResponse(erasure)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// eraseUserData deletes the user and everything that refers to them from
+// the database and inserts the erasure into the audit trail.
+func (h *UserHandler) eraseUserData(ctx context.Context, queries *db.Queries, user db.User, requestedBy pgtype.UUID) (db.UserErasure, error) {
+	ratings, err := queries.ListRatingsByUserId(ctx, user.ID)
+	if err != nil {
+		return db.UserErasure{}, err
+	}
+
+	if err := lockRatingCompetitions(ctx, queries, ratings); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	// The ratings are soft deleted first because their scores are needed to
+	// remove them from the stats.
+	deletedAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	for _, rating := range ratings {
+		deletedRating, err := queries.SoftDeleteRatingById(ctx, db.SoftDeleteRatingByIdParams{
+			ID:        rating.ID,
+			DeletedAt: deletedAt,
+		})
+		if err != nil {
+			return db.UserErasure{}, err
+		}
+
+		response, err := mapper.FromDbRatingToResponse(deletedRating, &user)
+		if err != nil {
+			return db.UserErasure{}, err
+		}
+
+		if err := h.statService.RemoveRatingFromStats(ctx, queries, response); err != nil {
+			return db.UserErasure{}, err
+		}
+	}
+
+	// The ratings of the user have already been removed from the stats.
+	if err := queries.DeleteRatingsByUserId(ctx, user.ID); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	if err := queries.DeleteUserStatsByUserId(ctx, user.ID); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	if err := queries.DeleteRatingEventsByUserId(ctx, user.ID); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	transferredGroups, err := queries.TransferGroupsOwnedByUserId(ctx, user.ID)
+	if err != nil {
+		return db.UserErasure{}, err
+	}
+
+	deletedGroups, err := queries.DeleteGroupsByOwnerId(ctx, user.ID)
+	if err != nil {
+		return db.UserErasure{}, err
+	}
+
+	if err := queries.DeleteGroupUsersByUserId(ctx, user.ID); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	if _, err := queries.DeleteUserById(ctx, user.ID); err != nil {
+		return db.UserErasure{}, err
+	}
+
+	return queries.InsertUserErasure(ctx, db.InsertUserErasureParams{
+		UserID:            user.ID,
+		RequestedBy:       requestedBy,
+		DeletedRatings:    int32(len(ratings)),
+		TransferredGroups: int32(transferredGroups),
+		DeletedGroups:     int32(deletedGroups),
+	})
+}
+
+func (h *UserHandler) listUserErasures(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+	if err := authUser.CheckIsAdmin(); err != nil {
+		return err
+	}
+
+	erasures, err := h.queries.ListUserErasures(ctx)
+	if err != nil {
+		return err
+	}
+
+	response, err := mapper.FromDbUserErasureListToResponse(erasures)
 	if err != nil {
 		return err
 	}
@@ -202,6 +369,106 @@ func (h *UserHandler) deleteUser(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// exportAuthUser returns all data stored about the user as a zip archive
+// of JSON files.
+func (h *UserHandler) exportAuthUser(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
+
+	user, err := h.queries.GetUserById(ctx, authUser.DbUser.ID)
+	if err != nil {
+		return err
+	}
+
+	profile, err := mapper.FromDbUserToResponse(user)
+	if err != nil {
+		return err
+	}
+
+	ratings, err := h.queries.ListRatingsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	ratingsResponse, err := mapper.FromDbRatingListToResponse(ratings, []db.User{user})
+	if err != nil {
+		return err
+	}
+
+	statsResponse := mapper.EmptyUserStatsResponse()
+	userStats, err := h.queries.GetStatsByUserId(ctx, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		globalStats, err := h.queries.GetGlobalStats(ctx)
+		if err != nil {
+			return err
+		}
+
+		statsResponse, err = mapper.FromDbUserStatsToResponse(userStats, globalStats, &user)
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshots, err := h.queries.ListUserStatsSnapshotsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	historyResponse, err := mapper.FromDbUserStatsSnapshotsToHistoryResponse(snapshots, user)
+	if err != nil {
+		return err
+	}
+
+	groups, err := h.queries.ListGroupsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	groupsResponse, err := mapper.FromDbGroupListToResponse(groups)
+	if err != nil {
+		return err
+	}
+
+	rankingPredictions, err := h.queries.ListAllRankingPredictionsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	rankingPredictionsResponse, err := mapper.FromDbRankingPredictionsToResponses(rankingPredictions)
+	if err != nil {
+		return err
+	}
+
+	qualificationPredictions, err := h.queries.ListAllQualificationPredictionsByUserId(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	qualificationPredictionsResponse, err := mapper.FromDbQualificationPredictionsToResponses(qualificationPredictions)
+	if err != nil {
+		return err
+	}
+
+	response := echoCtx.Response()
+	response.Header().Set(echo.HeaderContentType, "application/zip")
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"user-%s.zip\"", authUser.UserID))
+	response.WriteHeader(http.StatusOK)
+
+	return util.WriteJsonArchive(response, []util.ArchiveFile{
+		{Name: "profile.json", Data: profile},
+		{Name: "ratings.json", Data: ratingsResponse},
+		{Name: "stats.json", Data: statsResponse},
+		{Name: "stats-history.json", Data: historyResponse},
+		{Name: "groups.json", Data: groupsResponse},
+		{Name: "ranking-predictions.json", Data: rankingPredictionsResponse},
+		{Name: "qualification-predictions.json", Data: qualificationPredictionsResponse},
+	})
+}
+
 func (h *UserHandler) getProfilePicturePresignedURL(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
 	authUser := echoCtx.Get(authz.AuthUserContextKey).(*authz.AuthUser)
@@ -0,0 +1,67 @@
+package live
+
+import (
+	"context"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Service struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		pool:    pool,
+		queries: db.New(pool),
+	}
+}
+
+// Transition applies the given transition to the live state of the
+// competition. The competition row is locked for the duration of the
+// transition so that concurrent transitions cannot skip acts.
+func (s *Service) Transition(ctx context.Context, competitionId pgtype.UUID, transition func(State, []int32) (State, error)) (db.Competition, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return db.Competition{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, competitionId)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	acts, err := queries.ListActsByCompetitionId(ctx, competition.ID)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	runningOrder := make([]int32, 0, len(acts))
+	for _, act := range acts {
+		if act.Order.Valid {
+			runningOrder = append(runningOrder, act.Order.Int32)
+		}
+	}
+
+	state, err := transition(FromCompetition(competition), runningOrder)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	competition, err = queries.UpdateCompetitionLiveState(ctx, db.UpdateCompetitionLiveStateParams{
+		ID:           competition.ID,
+		LiveState:    state.Phase,
+		LiveActOrder: state.Order,
+	})
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	return competition, tx.Commit(ctx)
+}
@@ -0,0 +1,123 @@
+package live
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// State is the live state of a competition. Order is the running
+// order position of the act that is currently on stage.
+type State struct {
+	Phase db.LiveState
+	Order pgtype.Int4
+}
+
+func FromCompetition(c db.Competition) State {
+	return State{Phase: c.LiveState, Order: c.LiveActOrder}
+}
+
+// Advance moves the show on to the next act of the running order.
+func Advance(s State, runningOrder []int32) (State, error) {
+	orders := distinctSorted(runningOrder)
+
+	switch s.Phase {
+	case db.LiveStateLIVESTATEPENDING:
+		if len(orders) == 0 {
+			return s, echo.NewHTTPError(http.StatusConflict, "competition has no acts")
+		}
+		return performing(orders[0]), nil
+	case db.LiveStateLIVESTATEPERFORMING:
+		for _, order := range orders {
+			if order > s.Order.Int32 {
+				return performing(order), nil
+			}
+		}
+		return s, echo.NewHTTPError(http.StatusConflict, "the last act is already performing, close the competition instead")
+	default:
+		return s, echo.NewHTTPError(http.StatusConflict, "competition is already closed")
+	}
+}
+
+// Rewind moves the show back to the previous act of the running order.
+// Rewinding the first act resets the show to pending and rewinding a
+// closed show puts the last act back on stage.
+func Rewind(s State, runningOrder []int32) (State, error) {
+	orders := distinctSorted(runningOrder)
+
+	switch s.Phase {
+	case db.LiveStateLIVESTATEPERFORMING:
+		for i := len(orders) - 1; i >= 0; i-- {
+			if orders[i] < s.Order.Int32 {
+				return performing(orders[i]), nil
+			}
+		}
+		return State{Phase: db.LiveStateLIVESTATEPENDING}, nil
+	case db.LiveStateLIVESTATECLOSED:
+		if len(orders) == 0 {
+			return State{Phase: db.LiveStateLIVESTATEPENDING}, nil
+		}
+		return performing(orders[len(orders)-1]), nil
+	default:
+		return s, echo.NewHTTPError(http.StatusConflict, "competition has not started yet")
+	}
+}
+
+// Close ends the show. All acts count as performed afterwards.
+func Close(s State) (State, error) {
+	if s.Phase == db.LiveStateLIVESTATECLOSED {
+		return s, echo.NewHTTPError(http.StatusConflict, "competition is already closed")
+	}
+
+	return State{Phase: db.LiveStateLIVESTATECLOSED, Order: s.Order}, nil
+}
+
+// HasPerformed reports whether the act at the given running
+// order position has already been on stage.
+func HasPerformed(s State, order pgtype.Int4) bool {
+	switch s.Phase {
+	case db.LiveStateLIVESTATECLOSED:
+		return true
+	case db.LiveStateLIVESTATEPERFORMING:
+		return order.Valid && order.Int32 <= s.Order.Int32
+	default:
+		return false
+	}
+}
+
+// CanReorder reports whether an act may move from one running order
+// position to another. Acts that have been on stage keep their position
+// and no act may move into the part of the show that is already over.
+func CanReorder(s State, from pgtype.Int4, to pgtype.Int4) bool {
+	if from == to {
+		return true
+	}
+
+	return !HasPerformed(s, from) && !HasPerformed(s, to)
+}
+
+func performing(order int32) State {
+	return State{
+		Phase: db.LiveStateLIVESTATEPERFORMING,
+		Order: pgtype.Int4{Int32: order, Valid: true},
+	}
+}
+
+// distinctSorted returns the positions of the running order in ascending
+// order. Acts sharing a position are on stage at the same time.
+func distinctSorted(runningOrder []int32) []int32 {
+	seen := make(map[int32]bool, len(runningOrder))
+	var orders []int32
+	for _, order := range runningOrder {
+		if !seen[order] {
+			seen[order] = true
+			orders = append(orders, order)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i] < orders[j] })
+	return orders
+}
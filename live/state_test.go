@@ -0,0 +1,109 @@
+package live
+
+import (
+	"testing"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pending = State{Phase: db.LiveStateLIVESTATEPENDING}
+	closed  = State{Phase: db.LiveStateLIVESTATECLOSED, Order: pgtype.Int4{Int32: 3, Valid: true}}
+)
+
+func TestAdvance(t *testing.T) {
+	runningOrder := []int32{3, 1, 2, 2}
+
+	state, err := Advance(pending, runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, performing(1), state)
+
+	state, err = Advance(state, runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, performing(2), state)
+
+	state, err = Advance(state, runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, performing(3), state)
+
+	_, err = Advance(state, runningOrder)
+	assert.Error(t, err, "cannot advance past the last act")
+
+	_, err = Advance(closed, runningOrder)
+	assert.Error(t, err, "cannot advance a closed competition")
+
+	_, err = Advance(pending, nil)
+	assert.Error(t, err, "cannot advance a competition without acts")
+}
+
+func TestAdvanceSkipsGapsInRunningOrder(t *testing.T) {
+	state, err := Advance(performing(2), []int32{1, 2, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, performing(5), state)
+}
+
+func TestRewind(t *testing.T) {
+	runningOrder := []int32{1, 2, 3}
+
+	state, err := Rewind(closed, runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, performing(3), state)
+
+	state, err = Rewind(state, runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, performing(2), state)
+
+	state, err = Rewind(performing(1), runningOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, pending, state)
+
+	_, err = Rewind(pending, runningOrder)
+	assert.Error(t, err, "cannot rewind a competition that has not started")
+}
+
+func TestClose(t *testing.T) {
+	state, err := Close(performing(2))
+	assert.NoError(t, err)
+	assert.Equal(t, db.LiveStateLIVESTATECLOSED, state.Phase)
+
+	_, err = Close(closed)
+	assert.Error(t, err)
+}
+
+func TestHasPerformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    State
+		order    pgtype.Int4
+		expected bool
+	}{
+		{"Pending", pending, pgtype.Int4{Int32: 1, Valid: true}, false},
+		{"Earlier act", performing(2), pgtype.Int4{Int32: 1, Valid: true}, true},
+		{"Current act", performing(2), pgtype.Int4{Int32: 2, Valid: true}, true},
+		{"Later act", performing(2), pgtype.Int4{Int32: 3, Valid: true}, false},
+		{"Act without order", performing(2), pgtype.Int4{}, false},
+		{"Closed", closed, pgtype.Int4{Int32: 10, Valid: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HasPerformed(tt.state, tt.order))
+		})
+	}
+}
+
+func TestCanReorder(t *testing.T) {
+	performingSecond := performing(2)
+	first := pgtype.Int4{Int32: 1, Valid: true}
+	third := pgtype.Int4{Int32: 3, Valid: true}
+	fourth := pgtype.Int4{Int32: 4, Valid: true}
+
+	assert.True(t, CanReorder(pending, first, third))
+	assert.True(t, CanReorder(performingSecond, third, fourth))
+	assert.True(t, CanReorder(performingSecond, first, first))
+	assert.False(t, CanReorder(performingSecond, first, third), "an act that performed keeps its position")
+	assert.False(t, CanReorder(performingSecond, third, first), "no act moves into the past")
+	assert.False(t, CanReorder(closed, third, fourth))
+}
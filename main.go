@@ -12,6 +12,7 @@ import (
 	"github.com/hyperremix/song-contest-rater-service/authz"
 	"github.com/hyperremix/song-contest-rater-service/custommiddleware"
 	"github.com/hyperremix/song-contest-rater-service/handler"
+	"github.com/hyperremix/song-contest-rater-service/stat"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo-contrib/echoprometheus"
@@ -38,6 +39,9 @@ func setupPool(ctx context.Context) (*pgxpool.Pool, error) {
 		"lock_timeout":                        "10000",
 		"idle_in_transaction_session_timeout": "300000",
 		"search_path":                         "song_contest_rater_service",
+		// The page queries pick their sort field by argument. Custom plans
+		// fold the other sort fields away so that the sort indexes are used.
+		"plan_cache_mode": "force_custom_plan",
 	}
 
 	return pgxpool.NewWithConfig(ctx, config)
@@ -57,6 +61,15 @@ func main() {
 	}
 	defer connPool.Close()
 
+	if os.Getenv("SONGCONTESTRATERSERVICE_RECOMPUTE_STATS_ON_STARTUP") == "true" {
+		report, err := stat.NewService(connPool).Recompute(ctx, false)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+
+		e.Logger.Infof("recomputed stats of %d users, %d drifted", report.UsersChecked, len(report.Drifts))
+	}
+
 	go func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
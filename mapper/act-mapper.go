@@ -21,7 +21,7 @@ func FromDbActListToResponse(a []db.Act, r []db.Rating, u []db.User) (*pb.ListAc
 		acts = append(acts, proto)
 	}
 
-	sort.Slice(acts, func(i, j int) bool {
+	sort.SliceStable(acts, func(i, j int) bool {
 		return util.ManyRatingsSum(acts[i].Ratings) > util.ManyRatingsSum(acts[j].Ratings)
 	})
 
@@ -0,0 +1,166 @@
+package mapper
+
+import (
+	"math"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CategoryStatsResponse struct {
+	Category string  `json:"category"`
+	Count    int32   `json:"count"`
+	Avg      float64 `json:"avg"`
+	Variance float64 `json:"variance"`
+	StdDev   float64 `json:"std_dev"`
+	Min      int32   `json:"min"`
+	Max      int32   `json:"max"`
+}
+
+type ActCompetitionStatsResponse struct {
+	CompetitionId string                   `json:"competition_id"`
+	Rank          int32                    `json:"rank"`
+	Total         *CategoryStatsResponse   `json:"total"`
+	Categories    []*CategoryStatsResponse `json:"categories"`
+}
+
+type ActStatsResponse struct {
+	ActId        string                         `json:"act_id"`
+	Competitions []*ActCompetitionStatsResponse `json:"competitions"`
+}
+
+type CompetitionActStatsResponse struct {
+	ActId string                 `json:"act_id"`
+	Rank  int32                  `json:"rank"`
+	Total *CategoryStatsResponse `json:"total"`
+}
+
+type CompetitionStatsResponse struct {
+	CompetitionId     string                         `json:"competition_id"`
+	Total             *CategoryStatsResponse         `json:"total"`
+	Categories        []*CategoryStatsResponse       `json:"categories"`
+	Acts              []*CompetitionActStatsResponse `json:"acts"`
+	MostDivisiveActId string                         `json:"most_divisive_act_id,omitempty"`
+}
+
+func FromDbToActStatsResponse(actId pgtype.UUID, s []db.ListActStatsByActIdRow) (*ActStatsResponse, error) {
+	protoActId, err := FromDbToProtoId(actId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	competitions := make([]*ActCompetitionStatsResponse, 0)
+	competitionsById := make(map[pgtype.UUID]*ActCompetitionStatsResponse)
+	for _, stats := range s {
+		competition, ok := competitionsById[stats.CompetitionID]
+		if !ok {
+			competitionId, err := FromDbToProtoId(stats.CompetitionID)
+			if err != nil {
+				return nil, NewResponseBindingError(err)
+			}
+
+			competition = &ActCompetitionStatsResponse{
+				CompetitionId: competitionId,
+				Rank:          stats.Rank,
+				Categories:    make([]*CategoryStatsResponse, 0),
+			}
+			competitionsById[stats.CompetitionID] = competition
+			competitions = append(competitions, competition)
+		}
+
+		categoryStats, err := fromDbToCategoryStatsResponse(stats.Category, stats.RatingCount, stats.RatingAvg, stats.RatingVariance, stats.RatingMin, stats.RatingMax)
+		if err != nil {
+			return nil, err
+		}
+
+		if stats.Category == "" {
+			competition.Total = categoryStats
+			continue
+		}
+
+		competition.Categories = append(competition.Categories, categoryStats)
+	}
+
+	return &ActStatsResponse{
+		ActId:        protoActId,
+		Competitions: competitions,
+	}, nil
+}
+
+// FromDbToCompetitionStatsResponse maps the stats of the competition and the
+// total stats of its acts ranked by their average total. The most divisive
+// act is the act whose totals vary the most.
+func FromDbToCompetitionStatsResponse(competitionId pgtype.UUID, s []db.CompetitionStat, a []db.ListActTotalStatsByCompetitionIdRow) (*CompetitionStatsResponse, error) {
+	protoCompetitionId, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	response := &CompetitionStatsResponse{
+		CompetitionId: protoCompetitionId,
+		Categories:    make([]*CategoryStatsResponse, 0),
+		Acts:          make([]*CompetitionActStatsResponse, len(a)),
+	}
+
+	for _, stats := range s {
+		categoryStats, err := fromDbToCategoryStatsResponse(stats.Category, stats.RatingCount, stats.RatingAvg, stats.RatingVariance, stats.RatingMin, stats.RatingMax)
+		if err != nil {
+			return nil, err
+		}
+
+		if stats.Category == "" {
+			response.Total = categoryStats
+			continue
+		}
+
+		response.Categories = append(response.Categories, categoryStats)
+	}
+
+	var maxVariance float64
+	for i, stats := range a {
+		actId, err := FromDbToProtoId(stats.ActID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		total, err := fromDbToCategoryStatsResponse(stats.Category, stats.RatingCount, stats.RatingAvg, stats.RatingVariance, stats.RatingMin, stats.RatingMax)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Acts[i] = &CompetitionActStatsResponse{
+			ActId: actId,
+			Rank:  stats.Rank,
+			Total: total,
+		}
+
+		if total.Variance > maxVariance {
+			maxVariance = total.Variance
+			response.MostDivisiveActId = actId
+		}
+	}
+
+	return response, nil
+}
+
+func fromDbToCategoryStatsResponse(category string, count int32, avg pgtype.Numeric, variance pgtype.Numeric, min int32, max int32) (*CategoryStatsResponse, error) {
+	avgFloat, err := fromNumericToFloat64(avg)
+	if err != nil {
+		return nil, err
+	}
+
+	varianceFloat, err := fromNumericToFloat64(variance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CategoryStatsResponse{
+		Category: category,
+		Count:    count,
+		Avg:      avgFloat,
+		Variance: varianceFloat,
+		StdDev:   math.Sqrt(varianceFloat),
+		Min:      min,
+		Max:      max,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package mapper
+
+import (
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CategoryResponse struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Min    int32  `json:"min"`
+	Max    int32  `json:"max"`
+	Weight int32  `json:"weight"`
+}
+
+type ListCategoriesResponse struct {
+	CompetitionId string              `json:"competition_id"`
+	Categories    []*CategoryResponse `json:"categories"`
+}
+
+type CategoryRequest struct {
+	Name   string `json:"name"`
+	Min    int32  `json:"min"`
+	Max    int32  `json:"max"`
+	Weight int32  `json:"weight"`
+}
+
+type UpdateCategoriesRequest struct {
+	Id         string            `param:"id"`
+	Categories []CategoryRequest `json:"categories"`
+}
+
+func FromDbCategoryListToResponse(competitionId pgtype.UUID, c []db.CompetitionCategory) (*ListCategoriesResponse, error) {
+	protoCompetitionId, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	categories := make([]*CategoryResponse, len(c))
+	for i, category := range c {
+		id, err := FromDbToProtoId(category.ID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		categories[i] = &CategoryResponse{
+			Id:     id,
+			Name:   category.Name,
+			Min:    category.Min,
+			Max:    category.Max,
+			Weight: category.Weight,
+		}
+	}
+
+	return &ListCategoriesResponse{
+		CompetitionId: protoCompetitionId,
+		Categories:    categories,
+	}, nil
+}
+
+func FromDbCategoryListToUtil(c []db.CompetitionCategory) []util.Category {
+	categories := make([]util.Category, len(c))
+	for i, category := range c {
+		categories[i] = util.Category{
+			Name:   category.Name,
+			Min:    category.Min,
+			Max:    category.Max,
+			Weight: category.Weight,
+		}
+	}
+
+	return categories
+}
+
+// FromCategoryRequestListToUtil converts the requested categories, a
+// category without a weight counts once.
+func FromCategoryRequestListToUtil(c []CategoryRequest) []util.Category {
+	categories := make([]util.Category, len(c))
+	for i, category := range c {
+		weight := category.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		categories[i] = util.Category{
+			Name:   category.Name,
+			Min:    category.Min,
+			Max:    category.Max,
+			Weight: weight,
+		}
+	}
+
+	return categories
+}
+
+func FromUtilCategoryListToInsertCompetitionCategories(competitionId pgtype.UUID, c []util.Category) []db.InsertCompetitionCategoryParams {
+	params := make([]db.InsertCompetitionCategoryParams, len(c))
+	for i, category := range c {
+		params[i] = db.InsertCompetitionCategoryParams{
+			CompetitionID: competitionId,
+			Name:          category.Name,
+			Min:           category.Min,
+			Max:           category.Max,
+			Weight:        category.Weight,
+			Position:      int32(i + 1),
+		}
+	}
+
+	return params
+}
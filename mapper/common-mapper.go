@@ -1,7 +1,11 @@
 package mapper
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hyperremix/song-contest-rater-service/db"
@@ -27,6 +31,16 @@ func FromProtoToDbId(id string) (pgtype.UUID, error) {
 	return pgtype.UUID{Bytes: uuid, Valid: true}, nil
 }
 
+// FromProtoToNullableDbId returns an invalid id for an empty string
+// so that optional ids can be passed to nullable query parameters.
+func FromProtoToNullableDbId(id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+
+	return FromProtoToDbId(id)
+}
+
 func fromProtoToDbTimestamp(timestamp *timestamppb.Timestamp) pgtype.Timestamptz {
 	return pgtype.Timestamptz{Time: timestamp.AsTime(), Valid: true}
 }
@@ -75,3 +89,28 @@ func getUser(users []db.User, userId pgtype.UUID) *db.User {
 
 	return nil
 }
+
+// readCsvHeader returns the index of each of the known columns in the
+// header row of the reader and fails if a required column is missing.
+func readCsvHeader(reader *csv.Reader, known []string, required ...string) (map[string]int, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv must start with a header row")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if slices.Contains(known, name) {
+			columns[name] = i
+		}
+	}
+
+	for _, column := range required {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("csv is missing the %s column", column)
+		}
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,110 @@
+package mapper
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ExportCompetitionRequest struct {
+	Id     string `param:"id"`
+	Format string `query:"format"`
+}
+
+type RatingExportRow struct {
+	RatingId   string `json:"rating_id"`
+	UserId     string `json:"user_id"`
+	UserName   string `json:"user_name"`
+	ActId      string `json:"act_id"`
+	ArtistName string `json:"artist_name"`
+	SongName   string `json:"song_name"`
+	Order      *int32 `json:"order"`
+	Song       int32  `json:"song"`
+	Singing    int32  `json:"singing"`
+	Show       int32  `json:"show"`
+	Looks      int32  `json:"looks"`
+	Clothes    int32  `json:"clothes"`
+	Total      int32  `json:"total"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+var RatingExportColumns = []string{
+	"rating_id", "user_id", "user_name", "act_id", "artist_name", "song_name", "order",
+	"song", "singing", "show", "looks", "clothes", "total", "created_at", "updated_at",
+}
+
+func (r *RatingExportRow) CsvRecord() []string {
+	var order string
+	if r.Order != nil {
+		order = strconv.Itoa(int(*r.Order))
+	}
+
+	return []string{
+		r.RatingId, r.UserId, r.UserName, r.ActId, r.ArtistName, r.SongName, order,
+		strconv.Itoa(int(r.Song)), strconv.Itoa(int(r.Singing)), strconv.Itoa(int(r.Show)),
+		strconv.Itoa(int(r.Looks)), strconv.Itoa(int(r.Clothes)), strconv.Itoa(int(r.Total)),
+		r.CreatedAt, r.UpdatedAt,
+	}
+}
+
+// DbRatingExportRow is a rating of an export joined with its user and
+// act. The user and act columns are null if they cannot be found.
+type DbRatingExportRow struct {
+	ID         pgtype.UUID        `db:"id"`
+	UserID     pgtype.UUID        `db:"user_id"`
+	Firstname  pgtype.Text        `db:"firstname"`
+	Lastname   pgtype.Text        `db:"lastname"`
+	ActID      pgtype.UUID        `db:"act_id"`
+	ArtistName pgtype.Text        `db:"artist_name"`
+	SongName   pgtype.Text        `db:"song_name"`
+	Order      pgtype.Int4        `db:"order"`
+	Song       pgtype.Int4        `db:"song"`
+	Singing    pgtype.Int4        `db:"singing"`
+	Show       pgtype.Int4        `db:"show"`
+	Looks      pgtype.Int4        `db:"looks"`
+	Clothes    pgtype.Int4        `db:"clothes"`
+	Total      int32              `db:"total"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `db:"updated_at"`
+}
+
+// FromDbRatingExportToExportRow flattens a rating with its act and user
+// into a single row. The act and user columns are left empty if they
+// cannot be found.
+func FromDbRatingExportToExportRow(r DbRatingExportRow) (*RatingExportRow, error) {
+	ratingId, err := FromDbToProtoId(r.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	userId, err := FromDbToProtoId(r.UserID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	actId, err := FromDbToProtoId(r.ActID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	return &RatingExportRow{
+		RatingId:   ratingId,
+		UserId:     userId,
+		UserName:   strings.TrimSpace(r.Firstname.String + " " + r.Lastname.String),
+		ActId:      actId,
+		ArtistName: r.ArtistName.String,
+		SongName:   r.SongName.String,
+		Order:      fromInt4ToInt32Pointer(r.Order),
+		Song:       r.Song.Int32,
+		Singing:    r.Singing.Int32,
+		Show:       r.Show.Int32,
+		Looks:      r.Looks.Int32,
+		Clothes:    r.Clothes.Int32,
+		Total:      r.Total,
+		CreatedAt:  r.CreatedAt.Time.UTC().Format(time.RFC3339),
+		UpdatedAt:  r.UpdatedAt.Time.UTC().Format(time.RFC3339),
+	}, nil
+}
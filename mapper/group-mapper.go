@@ -0,0 +1,115 @@
+package mapper
+
+import (
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type GroupResponse struct {
+	Id         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	InviteCode string                 `json:"invite_code"`
+	OwnerId    string                 `json:"owner_id"`
+	Users      []*pb.UserResponse     `json:"users,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `json:"created_at"`
+	UpdatedAt  *timestamppb.Timestamp `json:"updated_at"`
+}
+
+type ListGroupsResponse struct {
+	Groups []*GroupResponse `json:"groups"`
+}
+
+type CreateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateGroupRequest struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type JoinGroupRequest struct {
+	InviteCode string `json:"invite_code"`
+}
+
+func FromDbGroupListToResponse(g []db.Group) (*ListGroupsResponse, error) {
+	groups := make([]*GroupResponse, 0, len(g))
+
+	for _, group := range g {
+		proto, err := FromDbGroupToResponse(group, make([]db.User, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		groups = append(groups, proto)
+	}
+
+	return &ListGroupsResponse{Groups: groups}, nil
+}
+
+func FromDbGroupToResponse(g db.Group, u []db.User) (*GroupResponse, error) {
+	id, err := FromDbToProtoId(g.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	ownerId, err := FromDbToProtoId(g.OwnerID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	userListResponse, err := FromDbUserListToResponse(u)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	return &GroupResponse{
+		Id:         id,
+		Name:       g.Name,
+		InviteCode: g.InviteCode,
+		OwnerId:    ownerId,
+		Users:      userListResponse.Users,
+		CreatedAt:  fromDbToProtoTimestamp(g.CreatedAt),
+		UpdatedAt:  fromDbToProtoTimestamp(g.UpdatedAt),
+	}, nil
+}
+
+func FromCreateRequestToInsertGroup(r *CreateGroupRequest, ownerId pgtype.UUID, inviteCode string) db.InsertGroupParams {
+	return db.InsertGroupParams{
+		Name:       r.Name,
+		InviteCode: inviteCode,
+		OwnerID:    ownerId,
+	}
+}
+
+func FromUpdateRequestToUpdateGroup(r *UpdateGroupRequest) (db.UpdateGroupParams, error) {
+	id, err := FromProtoToDbId(r.Id)
+	if err != nil {
+		return db.UpdateGroupParams{}, NewRequestBindingError(err)
+	}
+
+	return db.UpdateGroupParams{
+		ID:   id,
+		Name: r.Name,
+	}, nil
+}
+
+func FromDbGroupStatsToResponse(stats []db.ComputeUserStatsByGroupIdRow, groupStats db.ComputeGroupStatsByGroupIdRow, users []db.User) (*pb.ListUserStatsResponse, error) {
+	userStats := make([]db.UserStat, len(stats))
+	for i, stat := range stats {
+		userStats[i] = db.UserStat{
+			UserID:      stat.UserID,
+			RatingAvg:   stat.RatingAvg,
+			RatingCount: fromInt32ToInt4(stat.RatingCount),
+		}
+	}
+
+	globalStats := db.GlobalStat{
+		RatingAvg:   groupStats.RatingAvg,
+		RatingCount: fromInt32ToInt4(groupStats.RatingCount),
+	}
+
+	return FromDbUserStatListToResponse(userStats, globalStats, users)
+}
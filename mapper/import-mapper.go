@@ -0,0 +1,168 @@
+package mapper
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ImportActRequest struct {
+	ArtistName string `json:"artist_name"`
+	SongName   string `json:"song_name"`
+	ImageUrl   string `json:"image_url"`
+	Order      int32  `json:"order"`
+}
+
+type ImportCompetitionRequest struct {
+	Competition *pb.CreateCompetitionRequest `json:"competition"`
+	Acts        []ImportActRequest           `json:"acts"`
+}
+
+type ImportedActResponse struct {
+	Act     *pb.ActResponse `json:"act"`
+	Order   int32           `json:"order"`
+	Matched bool            `json:"matched"`
+}
+
+type ImportCompetitionResponse struct {
+	Competition *pb.CompetitionResponse `json:"competition"`
+	Acts        []*ImportedActResponse  `json:"acts"`
+	CreatedActs int32                   `json:"created_acts"`
+	MatchedActs int32                   `json:"matched_acts"`
+}
+
+var csvImportActColumns = []string{"artist_name", "song_name", "image_url", "order"}
+
+// FromCsvToImportActRequests reads the line-up of a competition from a CSV
+// file with a header row. The artist_name and song_name columns are
+// required, the image_url and order columns are optional.
+func FromCsvToImportActRequests(r io.Reader) ([]ImportActRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	columns, err := readCsvHeader(reader, csvImportActColumns, "artist_name", "song_name")
+	if err != nil {
+		return nil, err
+	}
+
+	acts := make([]ImportActRequest, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+
+		act := ImportActRequest{
+			ArtistName: strings.TrimSpace(record[columns["artist_name"]]),
+			SongName:   strings.TrimSpace(record[columns["song_name"]]),
+		}
+
+		if i, ok := columns["image_url"]; ok {
+			act.ImageUrl = strings.TrimSpace(record[i])
+		}
+
+		if i, ok := columns["order"]; ok && strings.TrimSpace(record[i]) != "" {
+			order, err := strconv.ParseInt(strings.TrimSpace(record[i]), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("csv line %d: order must be a number", line)
+			}
+
+			act.Order = int32(order)
+		}
+
+		acts = append(acts, act)
+	}
+
+	return acts, nil
+}
+
+// FromImportRequestToRunningOrder returns the order of every act. Acts are
+// ordered as listed if no order is given, giving the order for only some
+// of the acts is ambiguous.
+func FromImportRequestToRunningOrder(r *ImportCompetitionRequest) ([]int32, error) {
+	orders := make([]int32, len(r.Acts))
+
+	var given int
+	for i, act := range r.Acts {
+		orders[i] = act.Order
+		if act.Order != 0 {
+			given++
+		}
+	}
+
+	if given == 0 {
+		for i := range orders {
+			orders[i] = int32(i + 1)
+		}
+	} else if given != len(orders) {
+		return nil, errors.New("order must be given for all acts or for none")
+	}
+
+	return orders, nil
+}
+
+func FromImportActRequestToGetActByArtistAndSong(r ImportActRequest) db.GetActByArtistAndSongParams {
+	return db.GetActByArtistAndSongParams{
+		ArtistName: r.ArtistName,
+		SongName:   r.SongName,
+	}
+}
+
+func FromImportActRequestToInsertAct(r ImportActRequest) db.InsertActParams {
+	return db.InsertActParams{
+		ArtistName: r.ArtistName,
+		SongName:   r.SongName,
+		ImageUrl:   r.ImageUrl,
+	}
+}
+
+func FromImportToInsertCompetitionAct(competitionId pgtype.UUID, actId pgtype.UUID, order int32) db.InsertCompetitionActParams {
+	return db.InsertCompetitionActParams{
+		CompetitionID: competitionId,
+		ActID:         actId,
+		Order:         fromInt32ToInt4(order),
+	}
+}
+
+func FromDbImportToResponse(competition db.Competition, acts []db.Act, orders []int32, matched []bool) (*ImportCompetitionResponse, error) {
+	competitionResponse, err := FromDbCompetitionToResponse(competition)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	response := &ImportCompetitionResponse{
+		Competition: competitionResponse,
+		Acts:        make([]*ImportedActResponse, len(acts)),
+	}
+
+	for i, act := range acts {
+		actResponse, err := FromDbActToResponse(act, make([]db.Rating, 0), make([]db.User, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		response.Acts[i] = &ImportedActResponse{
+			Act:     actResponse,
+			Order:   orders[i],
+			Matched: matched[i],
+		}
+
+		if matched[i] {
+			response.MatchedActs++
+		} else {
+			response.CreatedActs++
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type LiveStateResponse struct {
+	CompetitionId     string                 `json:"competition_id"`
+	State             string                 `json:"state"`
+	ActOrder          int32                  `json:"act_order,omitempty"`
+	CurrentActs       []*pb.ActResponse      `json:"current_acts"`
+	PerformedActsOnly bool                   `json:"performed_acts_only"`
+	UpdatedAt         *timestamppb.Timestamp `json:"updated_at"`
+}
+
+type UpdateLiveSettingsRequest struct {
+	Id                string `param:"id"`
+	PerformedActsOnly bool   `json:"performed_acts_only"`
+}
+
+func FromDbToLiveStateResponse(c db.Competition, competitionActs []db.ListActsByCompetitionIdRow) (*LiveStateResponse, error) {
+	competitionId, err := FromDbToProtoId(c.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	currentActs := make([]*pb.ActResponse, 0)
+	if c.LiveState == db.LiveStateLIVESTATEPERFORMING {
+		for _, competitionAct := range competitionActs {
+			if competitionAct.Order != c.LiveActOrder {
+				continue
+			}
+
+			act, err := FromDbOrderedActToResponse(competitionAct, make([]db.Rating, 0), make([]db.User, 0))
+			if err != nil {
+				return nil, NewResponseBindingError(err)
+			}
+
+			currentActs = append(currentActs, act)
+		}
+	}
+
+	return &LiveStateResponse{
+		CompetitionId:     competitionId,
+		State:             string(c.LiveState),
+		ActOrder:          c.LiveActOrder.Int32,
+		CurrentActs:       currentActs,
+		PerformedActsOnly: c.PerformedActsOnly,
+		UpdatedAt:         fromDbToProtoTimestamp(c.UpdatedAt),
+	}, nil
+}
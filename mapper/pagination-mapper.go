@@ -0,0 +1,377 @@
+package mapper
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PageRequest struct {
+	Limit  int32  `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+}
+
+type ListRatingsRequest struct {
+	PageRequest
+	CompetitionId string `query:"competitionId"`
+	ActId         string `query:"actId"`
+	UserId        string `query:"userId"`
+}
+
+type ListActsRequest struct {
+	PageRequest
+	Search string `query:"search"`
+}
+
+type ListUsersRequest struct {
+	PageRequest
+	Search string `query:"search"`
+}
+
+type ListCompetitionsRequest struct {
+	PageRequest
+	Country  string `query:"country"`
+	Heat     string `query:"heat"`
+	Year     int32  `query:"year"`
+	SeasonId string `query:"seasonId"`
+}
+
+type ListUserStatsRequest struct {
+	PageRequest
+	MinRatingCount int32 `query:"minRatingCount"`
+}
+
+// The page responses embed the list responses so that the lists keep
+// their JSON shape and only gain the cursor of the next page.
+
+type ListRatingsPageResponse struct {
+	*pb.ListRatingsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ListActsPageResponse struct {
+	*pb.ListActsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ListUsersPageResponse struct {
+	*pb.ListUsersResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ListCompetitionsPageResponse struct {
+	*pb.ListCompetitionsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ListUserStatsPageResponse struct {
+	*pb.ListUserStatsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// keyset is the cursor of a page in the types the page queries compare
+// the sort fields with. Only the value of the type of the sort field and
+// the id are set.
+type keyset struct {
+	Id      pgtype.UUID
+	Text    pgtype.Text
+	Time    pgtype.Timestamptz
+	Int     pgtype.Int4
+	Numeric pgtype.Numeric
+}
+
+type sortType int
+
+const (
+	sortText sortType = iota
+	sortTime
+	sortInt
+	sortNumeric
+)
+
+var (
+	ratingSortTypes      = map[string]sortType{"createdAt": sortTime, "total": sortInt}
+	actSortTypes         = map[string]sortType{"artistName": sortText, "songName": sortText, "createdAt": sortTime}
+	userSortTypes        = map[string]sortType{"firstname": sortText, "lastname": sortText, "createdAt": sortTime}
+	competitionSortTypes = map[string]sortType{"startTime": sortTime, "city": sortText, "country": sortText, "createdAt": sortTime}
+	userStatsSortTypes   = map[string]sortType{"ratingAvg": sortNumeric, "ratingCount": sortInt}
+)
+
+func fromPageToKeyset(page util.Page, sortTypes map[string]sortType) (keyset, error) {
+	if page.After == nil {
+		return keyset{}, nil
+	}
+
+	id, err := FromProtoToDbId(page.After.Id)
+	if err != nil {
+		return keyset{}, err
+	}
+
+	k := keyset{Id: id}
+	switch sortTypes[page.SortBy] {
+	case sortText:
+		k.Text = pgtype.Text{String: page.After.Value, Valid: true}
+	case sortTime:
+		t, err := time.Parse(time.RFC3339Nano, page.After.Value)
+		if err != nil {
+			return keyset{}, NewRequestBindingError(err)
+		}
+		k.Time = pgtype.Timestamptz{Time: t, Valid: true}
+	case sortInt:
+		i, err := strconv.ParseInt(page.After.Value, 10, 32)
+		if err != nil {
+			return keyset{}, NewRequestBindingError(err)
+		}
+		k.Int = fromInt32ToInt4(int32(i))
+	case sortNumeric:
+		if err := k.Numeric.Scan(page.After.Value); err != nil {
+			return keyset{}, NewRequestBindingError(err)
+		}
+	}
+
+	return k, nil
+}
+
+func fromTimestampToCursorValue(t pgtype.Timestamptz) string {
+	return t.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func FromListRequestToListRatingsPage(request ListRatingsRequest, page util.Page) (db.ListRatingsPageParams, error) {
+	competitionId, err := FromProtoToNullableDbId(request.CompetitionId)
+	if err != nil {
+		return db.ListRatingsPageParams{}, err
+	}
+
+	actId, err := FromProtoToNullableDbId(request.ActId)
+	if err != nil {
+		return db.ListRatingsPageParams{}, err
+	}
+
+	userId, err := FromProtoToNullableDbId(request.UserId)
+	if err != nil {
+		return db.ListRatingsPageParams{}, err
+	}
+
+	after, err := fromPageToKeyset(page, ratingSortTypes)
+	if err != nil {
+		return db.ListRatingsPageParams{}, err
+	}
+
+	return db.ListRatingsPageParams{
+		CompetitionID: competitionId,
+		ActID:         actId,
+		UserID:        userId,
+		SortBy:        page.SortBy,
+		SortDesc:      page.Desc,
+		AfterID:       after.Id,
+		AfterTime:     after.Time,
+		AfterInt:      after.Int,
+		RowLimit:      page.FetchLimit(),
+	}, nil
+}
+
+// FromDbRatingToPosition returns the position of the rating in a list of
+// ratings sorted by sortBy.
+func FromDbRatingToPosition(sortBy string) func(db.Rating) (string, string, error) {
+	return func(r db.Rating) (string, string, error) {
+		id, err := FromDbToProtoId(r.ID)
+		if sortBy == "total" {
+			return strconv.Itoa(int(r.Total)), id, err
+		}
+
+		return fromTimestampToCursorValue(r.CreatedAt), id, err
+	}
+}
+
+func FromListRequestToListActsPage(request ListActsRequest, page util.Page) (db.ListActsPageParams, error) {
+	after, err := fromPageToKeyset(page, actSortTypes)
+	if err != nil {
+		return db.ListActsPageParams{}, err
+	}
+
+	return db.ListActsPageParams{
+		Search:    fromStringToNullableText(request.Search),
+		SortBy:    page.SortBy,
+		SortDesc:  page.Desc,
+		AfterID:   after.Id,
+		AfterText: after.Text,
+		AfterTime: after.Time,
+		RowLimit:  page.FetchLimit(),
+	}, nil
+}
+
+// FromDbActToPosition returns the position of the act in a list of acts
+// sorted by sortBy.
+func FromDbActToPosition(sortBy string) func(db.Act) (string, string, error) {
+	return func(a db.Act) (string, string, error) {
+		id, err := FromDbToProtoId(a.ID)
+		switch sortBy {
+		case "artistName":
+			return a.ArtistName, id, err
+		case "songName":
+			return a.SongName, id, err
+		default:
+			return fromTimestampToCursorValue(a.CreatedAt), id, err
+		}
+	}
+}
+
+func FromListRequestToListUsersPage(request ListUsersRequest, page util.Page) (db.ListUsersPageParams, error) {
+	after, err := fromPageToKeyset(page, userSortTypes)
+	if err != nil {
+		return db.ListUsersPageParams{}, err
+	}
+
+	return db.ListUsersPageParams{
+		Search:    fromStringToNullableText(request.Search),
+		SortBy:    page.SortBy,
+		SortDesc:  page.Desc,
+		AfterID:   after.Id,
+		AfterText: after.Text,
+		AfterTime: after.Time,
+		RowLimit:  page.FetchLimit(),
+	}, nil
+}
+
+// FromDbUserToPosition returns the position of the user in a list of
+// users sorted by sortBy.
+func FromDbUserToPosition(sortBy string) func(db.User) (string, string, error) {
+	return func(u db.User) (string, string, error) {
+		id, err := FromDbToProtoId(u.ID)
+		switch sortBy {
+		case "firstname":
+			return u.Firstname, id, err
+		case "lastname":
+			return u.Lastname, id, err
+		default:
+			return fromTimestampToCursorValue(u.CreatedAt), id, err
+		}
+	}
+}
+
+func FromListRequestToListCompetitionsPage(request ListCompetitionsRequest, page util.Page) (db.ListCompetitionsPageParams, error) {
+	heat := db.NullHeat{}
+	if request.Heat != "" {
+		value, ok := pb.Heat_value[request.Heat]
+		if !ok {
+			return db.ListCompetitionsPageParams{}, NewRequestBindingError(fmt.Errorf("unknown heat %s", request.Heat))
+		}
+
+		heat = db.NullHeat{Heat: fromRequestHeatToDb(pb.Heat(value)), Valid: true}
+	}
+
+	year := pgtype.Int4{}
+	if request.Year != 0 {
+		year = fromInt32ToInt4(request.Year)
+	}
+
+	seasonId, err := FromProtoToNullableDbId(request.SeasonId)
+	if err != nil {
+		return db.ListCompetitionsPageParams{}, err
+	}
+
+	after, err := fromPageToKeyset(page, competitionSortTypes)
+	if err != nil {
+		return db.ListCompetitionsPageParams{}, err
+	}
+
+	return db.ListCompetitionsPageParams{
+		Country:   fromStringToNullableText(request.Country),
+		Heat:      heat,
+		Year:      year,
+		SeasonID:  seasonId,
+		SortBy:    page.SortBy,
+		SortDesc:  page.Desc,
+		AfterID:   after.Id,
+		AfterText: after.Text,
+		AfterTime: after.Time,
+		RowLimit:  page.FetchLimit(),
+	}, nil
+}
+
+// FromDbCompetitionToPosition returns the position of the competition in
+// a list of competitions sorted by sortBy.
+func FromDbCompetitionToPosition(sortBy string) func(db.Competition) (string, string, error) {
+	return func(c db.Competition) (string, string, error) {
+		id, err := FromDbToProtoId(c.ID)
+		switch sortBy {
+		case "startTime":
+			return fromTimestampToCursorValue(c.StartTime), id, err
+		case "city":
+			return c.City, id, err
+		case "country":
+			return c.Country, id, err
+		default:
+			return fromTimestampToCursorValue(c.CreatedAt), id, err
+		}
+	}
+}
+
+func FromListRequestToListUserStatsPage(request ListUserStatsRequest, page util.Page) (db.ListUserStatsPageParams, error) {
+	minRatingCount := pgtype.Int4{}
+	if request.MinRatingCount != 0 {
+		minRatingCount = fromInt32ToInt4(request.MinRatingCount)
+	}
+
+	after, err := fromPageToKeyset(page, userStatsSortTypes)
+	if err != nil {
+		return db.ListUserStatsPageParams{}, err
+	}
+
+	return db.ListUserStatsPageParams{
+		MinRatingCount: minRatingCount,
+		SortBy:         page.SortBy,
+		SortDesc:       page.Desc,
+		AfterID:        after.Id,
+		AfterNumeric:   after.Numeric,
+		AfterInt:       after.Int,
+		RowLimit:       page.FetchLimit(),
+	}, nil
+}
+
+// FromDbUserStatsToPosition returns the position of the stats in a list of
+// user stats sorted by sortBy. Missing values are sorted as 0.
+func FromDbUserStatsToPosition(sortBy string) func(db.UserStat) (string, string, error) {
+	return func(s db.UserStat) (string, string, error) {
+		id, err := FromDbToProtoId(s.UserID)
+		if sortBy == "ratingCount" {
+			return strconv.Itoa(int(s.RatingCount.Int32)), id, err
+		}
+
+		if !s.RatingAvg.Valid {
+			return "0", id, err
+		}
+
+		value, valueErr := s.RatingAvg.Value()
+		if valueErr != nil {
+			return "", "", NewResponseBindingError(valueErr)
+		}
+
+		return value.(string), id, err
+	}
+}
+
+// FromDbUserStatsToUserIds returns the ids of the users of the stats so
+// that only the users of a page have to be queried.
+func FromDbUserStatsToUserIds(stats []db.UserStat) []pgtype.UUID {
+	ids := make([]pgtype.UUID, len(stats))
+	for i, stat := range stats {
+		ids[i] = stat.UserID
+	}
+
+	return ids
+}
+
+func fromStringToNullableText(s string) pgtype.Text {
+	if s == "" {
+		return pgtype.Text{}
+	}
+
+	return pgtype.Text{String: s, Valid: true}
+}
@@ -0,0 +1,142 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type UpdateRankingPredictionRequest struct {
+	Id     string   `param:"id"`
+	ActIds []string `json:"act_ids"`
+}
+
+type RankingPredictionResponse struct {
+	CompetitionId string   `json:"competition_id"`
+	ActIds        []string `json:"act_ids"`
+}
+
+type PredictionLeaderboardRequest struct {
+	CompetitionId string `query:"competitionId"`
+	Metric        string `query:"metric"`
+}
+
+type PredictionScoreResponse struct {
+	User             *pb.UserResponse `json:"user"`
+	Competitions     int32            `json:"competitions"`
+	KendallTau       float64          `json:"kendall_tau"`
+	PositionDistance float64          `json:"position_distance"`
+}
+
+type PredictionLeaderboardResponse struct {
+	Scores []*PredictionScoreResponse `json:"scores"`
+}
+
+// FromUpdateRequestToInsertRankingPredictions ranks the acts in the order
+// of the request, starting with position 1.
+func FromUpdateRequestToInsertRankingPredictions(userId pgtype.UUID, competitionId pgtype.UUID, r *UpdateRankingPredictionRequest) ([]db.InsertRankingPredictionParams, error) {
+	params := make([]db.InsertRankingPredictionParams, len(r.ActIds))
+	seen := make(map[pgtype.UUID]bool, len(r.ActIds))
+
+	for i, id := range r.ActIds {
+		actId, err := FromProtoToDbId(id)
+		if err != nil {
+			return nil, NewRequestBindingError(err)
+		}
+
+		if seen[actId] {
+			return nil, NewRequestBindingError(fmt.Errorf("act %s is ranked more than once", id))
+		}
+		seen[actId] = true
+
+		params[i] = db.InsertRankingPredictionParams{
+			UserID:        userId,
+			CompetitionID: competitionId,
+			ActID:         actId,
+			Position:      int32(i + 1),
+		}
+	}
+
+	if len(params) == 0 {
+		return nil, NewRequestBindingError(errors.New("at least one act has to be ranked"))
+	}
+
+	return params, nil
+}
+
+func FromDbRankingPredictionsToResponse(competitionId pgtype.UUID, p []db.RankingPrediction) (*RankingPredictionResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	actIds := make([]string, len(p))
+	for i, prediction := range p {
+		actIds[i], err = FromDbToProtoId(prediction.ActID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+	}
+
+	return &RankingPredictionResponse{CompetitionId: id, ActIds: actIds}, nil
+}
+
+// FromDbScoredPredictionsToRankedPairs keys the predicted and the actual
+// ranks by user and competition.
+func FromDbScoredPredictionsToRankedPairs(p []db.ListScoredRankingPredictionsRow) (map[string]map[string][]util.RankedPair, error) {
+	pairs := make(map[string]map[string][]util.RankedPair)
+	for _, prediction := range p {
+		userId, err := FromDbToProtoId(prediction.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		competitionId, err := FromDbToProtoId(prediction.CompetitionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := pairs[userId]; !ok {
+			pairs[userId] = make(map[string][]util.RankedPair)
+		}
+
+		pairs[userId][competitionId] = append(pairs[userId][competitionId], util.RankedPair{
+			Predicted: prediction.Position,
+			Actual:    prediction.Placement,
+		})
+	}
+
+	return pairs, nil
+}
+
+func FromPredictionScoresToLeaderboardResponse(scores []util.PredictionScore, users []db.User) (*PredictionLeaderboardResponse, error) {
+	response := &PredictionLeaderboardResponse{Scores: make([]*PredictionScoreResponse, 0, len(scores))}
+
+	for _, score := range scores {
+		userId, err := FromProtoToDbId(score.UserId)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		var userResponse *pb.UserResponse
+		if user := getUser(users, userId); user != nil {
+			userResponse, err = FromDbUserToResponse(*user)
+			if err != nil {
+				return nil, NewResponseBindingError(err)
+			}
+		}
+
+		response.Scores = append(response.Scores, &PredictionScoreResponse{
+			User:             userResponse,
+			Competitions:     score.Competitions,
+			KendallTau:       score.KendallTau,
+			PositionDistance: score.PositionDistance,
+		})
+	}
+
+	return response, nil
+}
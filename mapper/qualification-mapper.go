@@ -0,0 +1,275 @@
+package mapper
+
+import (
+	"sort"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type QualificationResponse struct {
+	CompetitionId       string                 `json:"competition_id"`
+	ActId               string                 `json:"act_id"`
+	TargetCompetitionId string                 `json:"target_competition_id"`
+	CreatedAt           *timestamppb.Timestamp `json:"created_at"`
+}
+
+type ListQualificationsResponse struct {
+	Qualifications []*QualificationResponse `json:"qualifications"`
+}
+
+type ListQualificationsRequest struct {
+	CompetitionId string `query:"competitionId"`
+}
+
+type CreateQualificationRequest struct {
+	CompetitionId       string `json:"competition_id"`
+	ActId               string `json:"act_id"`
+	TargetCompetitionId string `json:"target_competition_id"`
+}
+
+type DeleteQualificationRequest struct {
+	CompetitionID string `query:"competition_id"`
+	ActID         string `query:"act_id"`
+}
+
+type GetQualificationPredictionRequest struct {
+	CompetitionId string `query:"competitionId"`
+}
+
+type UpdateQualificationPredictionRequest struct {
+	CompetitionId string   `json:"competition_id"`
+	ActIds        []string `json:"act_ids"`
+}
+
+type QualificationPredictionResponse struct {
+	CompetitionId string   `json:"competition_id"`
+	ActIds        []string `json:"act_ids"`
+}
+
+type GetQualificationStatusRequest struct {
+	CompetitionId string `query:"competitionId"`
+}
+
+type UpdateQualificationStatusRequest struct {
+	CompetitionId string `json:"competition_id"`
+	ExpectedCount *int32 `json:"expected_count"`
+	IsFinal       bool   `json:"is_final"`
+}
+
+type QualificationStatusResponse struct {
+	CompetitionId  string `json:"competition_id"`
+	ExpectedCount  *int32 `json:"expected_count"`
+	QualifierCount int32  `json:"qualifier_count"`
+	IsFinal        bool   `json:"is_final"`
+	IsComplete     bool   `json:"is_complete"`
+}
+
+type ListQualificationPredictionScoresRequest struct {
+	CompetitionId string `query:"competitionId"`
+}
+
+type QualificationPredictionScoreResponse struct {
+	User         *pb.UserResponse `json:"user"`
+	Competitions int32            `json:"competitions"`
+	Predicted    int32            `json:"predicted"`
+	Correct      int32            `json:"correct"`
+	Qualified    int32            `json:"qualified"`
+	Accuracy     float64          `json:"accuracy"`
+}
+
+type ListQualificationPredictionScoresResponse struct {
+	Scores []*QualificationPredictionScoreResponse `json:"scores"`
+}
+
+func FromDbQualificationListToResponse(q []db.Qualification) (*ListQualificationsResponse, error) {
+	qualifications := make([]*QualificationResponse, 0, len(q))
+
+	for _, qualification := range q {
+		response, err := FromDbQualificationToResponse(qualification)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		qualifications = append(qualifications, response)
+	}
+
+	return &ListQualificationsResponse{Qualifications: qualifications}, nil
+}
+
+func FromDbQualificationToResponse(q db.Qualification) (*QualificationResponse, error) {
+	competitionId, err := FromDbToProtoId(q.CompetitionID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	actId, err := FromDbToProtoId(q.ActID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	targetCompetitionId, err := FromDbToProtoId(q.TargetCompetitionID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	return &QualificationResponse{
+		CompetitionId:       competitionId,
+		ActId:               actId,
+		TargetCompetitionId: targetCompetitionId,
+		CreatedAt:           fromDbToProtoTimestamp(q.CreatedAt),
+	}, nil
+}
+
+func FromCreateRequestToInsertQualification(r *CreateQualificationRequest) (db.InsertQualificationParams, error) {
+	competitionId, err := FromProtoToDbId(r.CompetitionId)
+	if err != nil {
+		return db.InsertQualificationParams{}, NewRequestBindingError(err)
+	}
+
+	actId, err := FromProtoToDbId(r.ActId)
+	if err != nil {
+		return db.InsertQualificationParams{}, NewRequestBindingError(err)
+	}
+
+	targetCompetitionId, err := FromProtoToDbId(r.TargetCompetitionId)
+	if err != nil {
+		return db.InsertQualificationParams{}, NewRequestBindingError(err)
+	}
+
+	return db.InsertQualificationParams{
+		CompetitionID:       competitionId,
+		ActID:               actId,
+		TargetCompetitionID: targetCompetitionId,
+	}, nil
+}
+
+func FromDeleteRequestToDeleteQualification(r *DeleteQualificationRequest) (db.DeleteQualificationParams, error) {
+	competitionId, err := FromProtoToDbId(r.CompetitionID)
+	if err != nil {
+		return db.DeleteQualificationParams{}, NewRequestBindingError(err)
+	}
+
+	actId, err := FromProtoToDbId(r.ActID)
+	if err != nil {
+		return db.DeleteQualificationParams{}, NewRequestBindingError(err)
+	}
+
+	return db.DeleteQualificationParams{CompetitionID: competitionId, ActID: actId}, nil
+}
+
+func FromUpdateRequestToUpsertQualificationStatus(r *UpdateQualificationStatusRequest) (db.UpsertQualificationStatusParams, error) {
+	competitionId, err := FromProtoToDbId(r.CompetitionId)
+	if err != nil {
+		return db.UpsertQualificationStatusParams{}, NewRequestBindingError(err)
+	}
+
+	expectedCount := pgtype.Int4{}
+	if r.ExpectedCount != nil {
+		expectedCount = pgtype.Int4{Int32: *r.ExpectedCount, Valid: true}
+	}
+
+	return db.UpsertQualificationStatusParams{
+		CompetitionID: competitionId,
+		ExpectedCount: expectedCount,
+		IsFinal:       r.IsFinal,
+	}, nil
+}
+
+func FromDbToQualificationStatusResponse(s db.GetQualificationStatusByCompetitionIdRow) (*QualificationStatusResponse, error) {
+	competitionId, err := FromDbToProtoId(s.CompetitionID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	var expectedCount *int32
+	if s.ExpectedCount.Valid {
+		expectedCount = &s.ExpectedCount.Int32
+	}
+
+	return &QualificationStatusResponse{
+		CompetitionId:  competitionId,
+		ExpectedCount:  expectedCount,
+		QualifierCount: s.QualifierCount,
+		IsFinal:        s.IsFinal,
+		IsComplete:     util.QualifiersComplete(s.QualifierCount, expectedCount, s.IsFinal),
+	}, nil
+}
+
+// FromUpdateRequestToActIds returns the distinct act ids of a prediction.
+func FromUpdateRequestToActIds(r *UpdateQualificationPredictionRequest) ([]pgtype.UUID, error) {
+	actIds := make([]pgtype.UUID, 0, len(r.ActIds))
+	seen := make(map[pgtype.UUID]bool, len(r.ActIds))
+
+	for _, id := range r.ActIds {
+		actId, err := FromProtoToDbId(id)
+		if err != nil {
+			return nil, NewRequestBindingError(err)
+		}
+
+		if seen[actId] {
+			continue
+		}
+		seen[actId] = true
+
+		actIds = append(actIds, actId)
+	}
+
+	return actIds, nil
+}
+
+func FromDbToQualificationPredictionResponse(competitionId pgtype.UUID, p []db.QualificationPrediction) (*QualificationPredictionResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	actIds := make([]string, len(p))
+	for i, prediction := range p {
+		actIds[i], err = FromDbToProtoId(prediction.ActID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+	}
+
+	return &QualificationPredictionResponse{CompetitionId: id, ActIds: actIds}, nil
+}
+
+// FromDbToQualificationPredictionScoresResponse returns the scores ordered
+// from the most to the least accurate user.
+func FromDbToQualificationPredictionScoresResponse(s []db.ListQualificationPredictionScoresRow, users []db.User) (*ListQualificationPredictionScoresResponse, error) {
+	scores := make([]*QualificationPredictionScoreResponse, 0, len(s))
+
+	for _, score := range s {
+		var userResponse *pb.UserResponse
+		if user := getUser(users, score.UserID); user != nil {
+			var err error
+			userResponse, err = FromDbUserToResponse(*user)
+			if err != nil {
+				return nil, NewResponseBindingError(err)
+			}
+		}
+
+		scores = append(scores, &QualificationPredictionScoreResponse{
+			User:         userResponse,
+			Competitions: score.CompetitionCount,
+			Predicted:    score.PredictionCount,
+			Correct:      score.CorrectCount,
+			Qualified:    score.QualifierCount,
+			Accuracy:     util.QualificationAccuracy(score.CorrectCount, score.PredictionCount, score.QualifierCount),
+		})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Accuracy != scores[j].Accuracy {
+			return scores[i].Accuracy > scores[j].Accuracy
+		}
+
+		return scores[i].Correct > scores[j].Correct
+	})
+
+	return &ListQualificationPredictionScoresResponse{Scores: scores}, nil
+}
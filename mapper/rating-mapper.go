@@ -3,6 +3,8 @@ package mapper
 import (
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func FromDbRatingListToResponse(r []db.Rating, u []db.User) (*pb.ListRatingsResponse, error) {
@@ -55,14 +57,47 @@ func FromDbRatingToResponse(r db.Rating, u *db.User) (*pb.RatingResponse, error)
 		Show:          r.Show.Int32,
 		Looks:         r.Looks.Int32,
 		Clothes:       r.Clothes.Int32,
-		Total:         r.Total.Int32,
+		Total:         r.Total,
 		User:          userResponse,
 		CreatedAt:     fromDbToProtoTimestamp(r.CreatedAt),
 		UpdatedAt:     fromDbToProtoTimestamp(r.UpdatedAt),
 	}, nil
 }
 
-func FromCreateRequestToInsertRating(c *pb.CreateRatingRequest, protoUserId string) (db.InsertRatingParams, error) {
+type CreateRatingRequest struct {
+	CompetitionId string           `json:"competition_id"`
+	ActId         string           `json:"act_id"`
+	Song          int32            `json:"song"`
+	Singing       int32            `json:"singing"`
+	Show          int32            `json:"show"`
+	Looks         int32            `json:"looks"`
+	Clothes       int32            `json:"clothes"`
+	Scores        map[string]int32 `json:"scores"`
+}
+
+type UpdateRatingRequest struct {
+	Id      string           `json:"id"`
+	Song    int32            `json:"song"`
+	Singing int32            `json:"singing"`
+	Show    int32            `json:"show"`
+	Looks   int32            `json:"looks"`
+	Clothes int32            `json:"clothes"`
+	Scores  map[string]int32 `json:"scores"`
+}
+
+type RatingScoreResponse struct {
+	Category string `json:"category"`
+	Score    int32  `json:"score"`
+	Weight   int32  `json:"weight"`
+}
+
+type RatingScoresResponse struct {
+	RatingId string                 `json:"rating_id"`
+	Total    int32                  `json:"total"`
+	Scores   []*RatingScoreResponse `json:"scores"`
+}
+
+func FromCreateRequestToInsertRating(c *CreateRatingRequest, protoUserId string, scores map[string]int32, categories []db.CompetitionCategory) (db.InsertRatingParams, error) {
 	competitionId, err := FromProtoToDbId(c.CompetitionId)
 	if err != nil {
 		return db.InsertRatingParams{}, NewRequestBindingError(err)
@@ -82,15 +117,16 @@ func FromCreateRequestToInsertRating(c *pb.CreateRatingRequest, protoUserId stri
 		CompetitionID: competitionId,
 		ActID:         actId,
 		UserID:        userId,
-		Song:          fromInt32ToInt4(c.Song),
-		Singing:       fromInt32ToInt4(c.Singing),
-		Show:          fromInt32ToInt4(c.Show),
-		Looks:         fromInt32ToInt4(c.Looks),
-		Clothes:       fromInt32ToInt4(c.Clothes),
+		Song:          fromScoreToInt4(scores, "song"),
+		Singing:       fromScoreToInt4(scores, "singing"),
+		Show:          fromScoreToInt4(scores, "show"),
+		Looks:         fromScoreToInt4(scores, "looks"),
+		Clothes:       fromScoreToInt4(scores, "clothes"),
+		Total:         util.WeightedSum(scores, FromDbCategoryListToUtil(categories)),
 	}, nil
 }
 
-func FromUpdateRequestToUpdateRating(c *pb.UpdateRatingRequest) (db.UpdateRatingParams, error) {
+func FromUpdateRequestToUpdateRating(c *UpdateRatingRequest, scores map[string]int32, categories []db.CompetitionCategory) (db.UpdateRatingParams, error) {
 	id, err := FromProtoToDbId(c.Id)
 	if err != nil {
 		return db.UpdateRatingParams{}, NewRequestBindingError(err)
@@ -98,10 +134,98 @@ func FromUpdateRequestToUpdateRating(c *pb.UpdateRatingRequest) (db.UpdateRating
 
 	return db.UpdateRatingParams{
 		ID:      id,
-		Song:    fromInt32ToInt4(c.Song),
-		Singing: fromInt32ToInt4(c.Singing),
-		Show:    fromInt32ToInt4(c.Show),
-		Looks:   fromInt32ToInt4(c.Looks),
-		Clothes: fromInt32ToInt4(c.Clothes),
+		Song:    fromScoreToInt4(scores, "song"),
+		Singing: fromScoreToInt4(scores, "singing"),
+		Show:    fromScoreToInt4(scores, "show"),
+		Looks:   fromScoreToInt4(scores, "looks"),
+		Clothes: fromScoreToInt4(scores, "clothes"),
+		Total:   util.WeightedSum(scores, FromDbCategoryListToUtil(categories)),
+	}, nil
+}
+
+// FromCreateRequestToScores returns the validated scores of the request
+// by category name. Clients that do not send scores rate the legacy
+// song, singing, show, looks and clothes categories.
+func FromCreateRequestToScores(c *CreateRatingRequest, categories []db.CompetitionCategory) (map[string]int32, error) {
+	return fromRequestToScores(c.Scores, legacyScores(c.Song, c.Singing, c.Show, c.Looks, c.Clothes), categories)
+}
+
+func FromUpdateRequestToScores(c *UpdateRatingRequest, categories []db.CompetitionCategory) (map[string]int32, error) {
+	return fromRequestToScores(c.Scores, legacyScores(c.Song, c.Singing, c.Show, c.Looks, c.Clothes), categories)
+}
+
+func fromRequestToScores(scores map[string]int32, legacy map[string]int32, categories []db.CompetitionCategory) (map[string]int32, error) {
+	if len(scores) == 0 {
+		scores = make(map[string]int32)
+		for _, category := range categories {
+			if score, ok := legacy[category.Name]; ok {
+				scores[category.Name] = score
+			}
+		}
+	}
+
+	if err := util.ValidateScores(scores, FromDbCategoryListToUtil(categories)); err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+func legacyScores(song, singing, show, looks, clothes int32) map[string]int32 {
+	return map[string]int32{
+		"song":    song,
+		"singing": singing,
+		"show":    show,
+		"looks":   looks,
+		"clothes": clothes,
+	}
+}
+
+func fromScoreToInt4(scores map[string]int32, name string) pgtype.Int4 {
+	score, ok := scores[name]
+	if !ok {
+		return pgtype.Int4{}
+	}
+
+	return fromInt32ToInt4(score)
+}
+
+func FromScoresToInsertRatingScores(ratingId pgtype.UUID, scores map[string]int32, categories []db.CompetitionCategory) []db.InsertRatingScoreParams {
+	params := make([]db.InsertRatingScoreParams, 0, len(categories))
+	for _, category := range categories {
+		score, ok := scores[category.Name]
+		if !ok {
+			continue
+		}
+
+		params = append(params, db.InsertRatingScoreParams{
+			RatingID:   ratingId,
+			CategoryID: category.ID,
+			Score:      score,
+		})
+	}
+
+	return params
+}
+
+func FromDbToRatingScoresResponse(r db.Rating, s []db.ListRatingScoresByRatingIdRow) (*RatingScoresResponse, error) {
+	ratingId, err := FromDbToProtoId(r.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	scores := make([]*RatingScoreResponse, len(s))
+	for i, score := range s {
+		scores[i] = &RatingScoreResponse{
+			Category: score.Name,
+			Score:    score.Score,
+			Weight:   score.Weight,
+		}
+	}
+
+	return &RatingScoresResponse{
+		RatingId: ratingId,
+		Total:    r.Total,
+		Scores:   scores,
 	}, nil
 }
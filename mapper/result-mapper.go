@@ -0,0 +1,273 @@
+package mapper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CompetitionResultRequest struct {
+	ActId          string `json:"act_id"`
+	Placement      int32  `json:"placement"`
+	Points         *int32 `json:"points,omitempty"`
+	JuryPoints     *int32 `json:"jury_points,omitempty"`
+	TelevotePoints *int32 `json:"televote_points,omitempty"`
+}
+
+type UpdateCompetitionResultsRequest struct {
+	Id      string                     `param:"id"`
+	Results []CompetitionResultRequest `json:"results"`
+}
+
+type CompetitionResultResponse struct {
+	ActId          string `json:"act_id"`
+	Placement      int32  `json:"placement"`
+	Points         *int32 `json:"points,omitempty"`
+	JuryPoints     *int32 `json:"jury_points,omitempty"`
+	TelevotePoints *int32 `json:"televote_points,omitempty"`
+}
+
+type ListCompetitionResultsResponse struct {
+	CompetitionId string                       `json:"competition_id"`
+	Results       []*CompetitionResultResponse `json:"results"`
+}
+
+type CompetitionComparisonRequest struct {
+	Id    string `param:"id"`
+	Limit int32  `query:"limit"`
+}
+
+type ActComparisonResponse struct {
+	Act           *pb.ActResponse `json:"act"`
+	CommunityRank int32           `json:"community_rank"`
+	OfficialRank  int32           `json:"official_rank"`
+	Difference    int32           `json:"difference"`
+}
+
+type CompetitionComparisonResponse struct {
+	CompetitionId string                   `json:"competition_id"`
+	KendallTau    float64                  `json:"kendall_tau"`
+	Spearman      float64                  `json:"spearman"`
+	Acts          []*ActComparisonResponse `json:"acts"`
+	OverRated     []*ActComparisonResponse `json:"over_rated"`
+	UnderRated    []*ActComparisonResponse `json:"under_rated"`
+}
+
+var csvResultColumns = []string{"act_id", "placement", "points", "jury_points", "televote_points"}
+
+// FromCsvToCompetitionResultRequests reads official results from a CSV file
+// with a header row. The act_id and placement columns are required, the
+// points, jury_points and televote_points columns are optional.
+func FromCsvToCompetitionResultRequests(r io.Reader) ([]CompetitionResultRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	columns, err := readCsvHeader(reader, csvResultColumns, "act_id", "placement")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CompetitionResultRequest, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+
+		values := make(map[string]*int32, len(columns))
+		for column, i := range columns {
+			if column == "act_id" || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+
+			value, err := strconv.ParseInt(strings.TrimSpace(record[i]), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("csv line %d: %s must be a number", line, column)
+			}
+
+			number := int32(value)
+			values[column] = &number
+		}
+
+		if values["placement"] == nil {
+			return nil, fmt.Errorf("csv line %d: placement is required", line)
+		}
+
+		results = append(results, CompetitionResultRequest{
+			ActId:          strings.TrimSpace(record[columns["act_id"]]),
+			Placement:      *values["placement"],
+			Points:         values["points"],
+			JuryPoints:     values["jury_points"],
+			TelevotePoints: values["televote_points"],
+		})
+	}
+
+	return results, nil
+}
+
+func FromResultsRequestToInsertCompetitionResults(competitionId pgtype.UUID, r []CompetitionResultRequest) ([]db.InsertCompetitionResultParams, error) {
+	params := make([]db.InsertCompetitionResultParams, len(r))
+	for i, result := range r {
+		actId, err := FromProtoToDbId(result.ActId)
+		if err != nil {
+			return nil, NewRequestBindingError(err)
+		}
+
+		params[i] = db.InsertCompetitionResultParams{
+			CompetitionID:  competitionId,
+			ActID:          actId,
+			Placement:      result.Placement,
+			Points:         fromInt32PointerToInt4(result.Points),
+			JuryPoints:     fromInt32PointerToInt4(result.JuryPoints),
+			TelevotePoints: fromInt32PointerToInt4(result.TelevotePoints),
+		}
+	}
+
+	return params, nil
+}
+
+func FromDbCompetitionResultListToResponse(competitionId pgtype.UUID, r []db.CompetitionResult) (*ListCompetitionResultsResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	results := make([]*CompetitionResultResponse, len(r))
+	for i, result := range r {
+		actId, err := FromDbToProtoId(result.ActID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		results[i] = &CompetitionResultResponse{
+			ActId:          actId,
+			Placement:      result.Placement,
+			Points:         fromInt4ToInt32Pointer(result.Points),
+			JuryPoints:     fromInt4ToInt32Pointer(result.JuryPoints),
+			TelevotePoints: fromInt4ToInt32Pointer(result.TelevotePoints),
+		}
+	}
+
+	return &ListCompetitionResultsResponse{CompetitionId: id, Results: results}, nil
+}
+
+func FromDbActStatsToRatingAvgsByAct(s []db.ListActTotalStatsByCompetitionIdRow) (map[string]float64, error) {
+	ratingAvgs := make(map[string]float64, len(s))
+	for _, stats := range s {
+		actId, err := FromDbToProtoId(stats.ActID)
+		if err != nil {
+			return nil, err
+		}
+
+		ratingAvgs[actId], err = fromNumericToFloat64(stats.RatingAvg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ratingAvgs, nil
+}
+
+func FromDbResultsToPlacementsByAct(r []db.CompetitionResult) (map[string]int32, error) {
+	placements := make(map[string]int32, len(r))
+	for _, result := range r {
+		actId, err := FromDbToProtoId(result.ActID)
+		if err != nil {
+			return nil, err
+		}
+
+		placements[actId] = result.Placement
+	}
+
+	return placements, nil
+}
+
+// FromComparisonToResponse lists up to limit acts the community ranked
+// higher than the official results as over-rated and up to limit acts it
+// ranked lower as under-rated, each starting with the biggest difference.
+func FromComparisonToResponse(competitionId pgtype.UUID, comparison util.Comparison, a []db.ListActsByCompetitionIdRow, limit int) (*CompetitionComparisonResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	acts := make(map[string]*pb.ActResponse, len(a))
+	for _, act := range a {
+		actResponse, err := FromDbOrderedActToResponse(act, make([]db.Rating, 0), make([]db.User, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		acts[actResponse.Id] = actResponse
+	}
+
+	response := &CompetitionComparisonResponse{
+		CompetitionId: id,
+		KendallTau:    comparison.KendallTau,
+		Spearman:      comparison.Spearman,
+		Acts:          make([]*ActComparisonResponse, len(comparison.Acts)),
+		OverRated:     make([]*ActComparisonResponse, 0, limit),
+		UnderRated:    make([]*ActComparisonResponse, 0, limit),
+	}
+
+	for i, act := range comparison.Acts {
+		response.Acts[i] = &ActComparisonResponse{
+			Act:           acts[act.Id],
+			CommunityRank: act.CommunityRank,
+			OfficialRank:  act.OfficialRank,
+			Difference:    act.Difference(),
+		}
+	}
+
+	byDifference := make([]*ActComparisonResponse, len(response.Acts))
+	copy(byDifference, response.Acts)
+	sort.SliceStable(byDifference, func(i, j int) bool {
+		return byDifference[i].Difference > byDifference[j].Difference
+	})
+
+	for _, act := range byDifference {
+		if len(response.OverRated) >= limit || act.Difference <= 0 {
+			break
+		}
+
+		response.OverRated = append(response.OverRated, act)
+	}
+
+	for i := len(byDifference) - 1; i >= 0; i-- {
+		act := byDifference[i]
+		if len(response.UnderRated) >= limit || act.Difference >= 0 {
+			break
+		}
+
+		response.UnderRated = append(response.UnderRated, act)
+	}
+
+	return response, nil
+}
+
+func fromInt32PointerToInt4(i *int32) pgtype.Int4 {
+	if i == nil {
+		return pgtype.Int4{}
+	}
+
+	return fromInt32ToInt4(*i)
+}
+
+func fromInt4ToInt32Pointer(i pgtype.Int4) *int32 {
+	if !i.Valid {
+		return nil
+	}
+
+	return &i.Int32
+}
@@ -0,0 +1,150 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type UpdateRunningOrderRequest struct {
+	Id     string   `param:"id"`
+	ActIds []string `json:"act_ids"`
+}
+
+type SwapRunningOrderRequest struct {
+	Id     string   `param:"id"`
+	ActIds []string `json:"act_ids"`
+}
+
+type RunningOrderEntryResponse struct {
+	ActId string `json:"act_id"`
+	Order *int32 `json:"order"`
+}
+
+type RunningOrderResponse struct {
+	CompetitionId string                       `json:"competition_id"`
+	Acts          []*RunningOrderEntryResponse `json:"acts"`
+}
+
+type RunningOrderValidationResponse struct {
+	CompetitionId   string   `json:"competition_id"`
+	Valid           bool     `json:"valid"`
+	Gaps            []int32  `json:"gaps"`
+	Duplicates      []int32  `json:"duplicates"`
+	UnorderedActIds []string `json:"unordered_act_ids"`
+}
+
+// FromUpdateRequestToRunningOrder returns the act ids in the order of the
+// request, the first act opens the show.
+func FromUpdateRequestToRunningOrder(r *UpdateRunningOrderRequest) ([]pgtype.UUID, error) {
+	actIds, err := fromProtoToDistinctDbIds(r.ActIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actIds) == 0 {
+		return nil, NewRequestBindingError(errors.New("at least one act has to be ordered"))
+	}
+
+	return actIds, nil
+}
+
+func FromSwapRequestToActIds(r *SwapRunningOrderRequest) (pgtype.UUID, pgtype.UUID, error) {
+	actIds, err := fromProtoToDistinctDbIds(r.ActIds)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, err
+	}
+
+	if len(actIds) != 2 {
+		return pgtype.UUID{}, pgtype.UUID{}, NewRequestBindingError(errors.New("exactly two acts have to be swapped"))
+	}
+
+	return actIds[0], actIds[1], nil
+}
+
+// FromDbToRunningOrderResponse lists the acts by their order, acts without
+// an order come last.
+func FromDbToRunningOrderResponse(competitionId pgtype.UUID, a []db.ListActsByCompetitionIdRow) (*RunningOrderResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	acts := make([]*RunningOrderEntryResponse, len(a))
+	for i, act := range a {
+		actId, err := FromDbToProtoId(act.ID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		acts[i] = &RunningOrderEntryResponse{ActId: actId, Order: fromInt4ToInt32Pointer(act.Order)}
+	}
+
+	sort.SliceStable(acts, func(i, j int) bool {
+		if acts[i].Order == nil || acts[j].Order == nil {
+			return acts[j].Order == nil && acts[i].Order != nil
+		}
+
+		return *acts[i].Order < *acts[j].Order
+	})
+
+	return &RunningOrderResponse{CompetitionId: id, Acts: acts}, nil
+}
+
+func FromDbToRunningOrderValidationResponse(competitionId pgtype.UUID, a []db.ListActsByCompetitionIdRow) (*RunningOrderValidationResponse, error) {
+	id, err := FromDbToProtoId(competitionId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	orders := make([]int32, 0, len(a))
+	unorderedActIds := make([]string, 0)
+	for _, act := range a {
+		if act.Order.Valid {
+			orders = append(orders, act.Order.Int32)
+			continue
+		}
+
+		actId, err := FromDbToProtoId(act.ID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		unorderedActIds = append(unorderedActIds, actId)
+	}
+
+	issues := util.FindRunningOrderIssues(orders)
+
+	return &RunningOrderValidationResponse{
+		CompetitionId:   id,
+		Valid:           len(issues.Gaps) == 0 && len(issues.Duplicates) == 0 && len(unorderedActIds) == 0,
+		Gaps:            issues.Gaps,
+		Duplicates:      issues.Duplicates,
+		UnorderedActIds: unorderedActIds,
+	}, nil
+}
+
+func fromProtoToDistinctDbIds(ids []string) ([]pgtype.UUID, error) {
+	dbIds := make([]pgtype.UUID, len(ids))
+	seen := make(map[pgtype.UUID]bool, len(ids))
+
+	for i, id := range ids {
+		dbId, err := FromProtoToDbId(id)
+		if err != nil {
+			return nil, NewRequestBindingError(err)
+		}
+
+		if seen[dbId] {
+			return nil, NewRequestBindingError(fmt.Errorf("act %s is listed more than once", id))
+		}
+		seen[dbId] = true
+
+		dbIds[i] = dbId
+	}
+
+	return dbIds, nil
+}
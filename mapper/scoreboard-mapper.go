@@ -0,0 +1,101 @@
+package mapper
+
+import (
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ScoreboardEntryResponse struct {
+	Rank        int32           `json:"rank"`
+	Act         *pb.ActResponse `json:"act"`
+	Points      int32           `json:"points"`
+	Voters      int32           `json:"voters"`
+	PointsCount map[int32]int32 `json:"points_count"`
+}
+
+type ScoreboardResponse struct {
+	CompetitionId string                     `json:"competition_id"`
+	Ballots       int32                      `json:"ballots"`
+	Entries       []*ScoreboardEntryResponse `json:"entries"`
+}
+
+func FromDbToScoreboardResponse(c db.Competition, competitionActs []db.ListActsByCompetitionIdRow, r []db.Rating, s []db.ListRatingScoresByCompetitionIdRow) (*ScoreboardResponse, error) {
+	competitionId, err := FromDbToProtoId(c.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	acts := make(map[string]*pb.ActResponse, len(competitionActs))
+	runningOrder := make(map[string]int32, len(competitionActs))
+	for _, competitionAct := range competitionActs {
+		act, err := FromDbOrderedActToResponse(competitionAct, make([]db.Rating, 0), make([]db.User, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		acts[act.Id] = act
+		runningOrder[act.Id] = act.Order
+	}
+
+	ballots, err := fromDbRatingsToBallots(r, s)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	scoreboard := util.Scoreboard(runningOrder, ballots)
+
+	entries := make([]*ScoreboardEntryResponse, len(scoreboard))
+	for i, entry := range scoreboard {
+		entries[i] = &ScoreboardEntryResponse{
+			Rank:        int32(i + 1),
+			Act:         acts[entry.ActId],
+			Points:      entry.Points,
+			Voters:      entry.Voters,
+			PointsCount: entry.PointsCount,
+		}
+	}
+
+	return &ScoreboardResponse{
+		CompetitionId: competitionId,
+		Ballots:       int32(len(ballots)),
+		Entries:       entries,
+	}, nil
+}
+
+// fromDbRatingsToBallots groups the ratings by user so that every
+// user's ratings form a single ballot. The scores have to be ordered by
+// category so that ties are broken in category order.
+func fromDbRatingsToBallots(r []db.Rating, s []db.ListRatingScoresByCompetitionIdRow) ([][]util.BallotRating, error) {
+	scoresByRating := make(map[pgtype.UUID][]int32, len(r))
+	for _, score := range s {
+		scoresByRating[score.RatingID] = append(scoresByRating[score.RatingID], score.Score)
+	}
+
+	var userIds []pgtype.UUID
+	ballotsByUser := make(map[pgtype.UUID][]util.BallotRating)
+
+	for _, rating := range r {
+		actId, err := FromDbToProtoId(rating.ActID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := ballotsByUser[rating.UserID]; !ok {
+			userIds = append(userIds, rating.UserID)
+		}
+		ballotsByUser[rating.UserID] = append(ballotsByUser[rating.UserID], util.BallotRating{
+			ActId:  actId,
+			Total:  rating.Total,
+			Scores: scoresByRating[rating.ID],
+		})
+	}
+
+	ballots := make([][]util.BallotRating, len(userIds))
+	for i, userId := range userIds {
+		ballots[i] = ballotsByUser[userId]
+	}
+
+	return ballots, nil
+}
@@ -0,0 +1,197 @@
+package mapper
+
+import (
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type SeasonResponse struct {
+	Id           string                    `json:"id"`
+	Name         string                    `json:"name"`
+	Year         int32                     `json:"year"`
+	Competitions []*pb.CompetitionResponse `json:"competitions,omitempty"`
+	CreatedAt    *timestamppb.Timestamp    `json:"created_at"`
+	UpdatedAt    *timestamppb.Timestamp    `json:"updated_at"`
+}
+
+type ListSeasonsResponse struct {
+	Seasons []*SeasonResponse `json:"seasons"`
+}
+
+type CreateSeasonRequest struct {
+	Name string `json:"name"`
+	Year int32  `json:"year"`
+}
+
+type UpdateSeasonRequest struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Year int32  `json:"year"`
+}
+
+type SeasonCompetitionRequest struct {
+	Id            string `param:"id"`
+	CompetitionId string `param:"competitionId"`
+}
+
+// SeasonCompetitionResponse is a competition with the season it belongs
+// to, the season is null once the competition is removed from it.
+type SeasonCompetitionResponse struct {
+	*pb.CompetitionResponse
+	SeasonId *string `json:"season_id"`
+}
+
+// HeatProgressionResponse lists the acts of a semi-final by whether they
+// were marked as qualifiers of the semi-final, independent of whether the
+// line-up of the final has been built yet.
+type HeatProgressionResponse struct {
+	CompetitionId string            `json:"competition_id"`
+	Qualified     []*pb.ActResponse `json:"qualified"`
+	Eliminated    []*pb.ActResponse `json:"eliminated"`
+}
+
+type SeasonProgressionResponse struct {
+	SeasonId string                     `json:"season_id"`
+	Heats    []*HeatProgressionResponse `json:"heats"`
+}
+
+func FromDbToSeasonCompetitionResponse(c db.Competition) (*SeasonCompetitionResponse, error) {
+	competition, err := FromDbCompetitionToResponse(c)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SeasonCompetitionResponse{CompetitionResponse: competition}
+	if c.SeasonID.Valid {
+		seasonId, err := FromDbToProtoId(c.SeasonID)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		response.SeasonId = &seasonId
+	}
+
+	return response, nil
+}
+
+func FromDbSeasonListToResponse(s []db.Season) (*ListSeasonsResponse, error) {
+	seasons := make([]*SeasonResponse, 0, len(s))
+
+	for _, season := range s {
+		response, err := FromDbSeasonToResponse(season, make([]db.Competition, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		seasons = append(seasons, response)
+	}
+
+	return &ListSeasonsResponse{Seasons: seasons}, nil
+}
+
+func FromDbSeasonToResponse(s db.Season, c []db.Competition) (*SeasonResponse, error) {
+	id, err := FromDbToProtoId(s.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	competitions, err := FromDbCompetitionListToResponse(c)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	return &SeasonResponse{
+		Id:           id,
+		Name:         s.Name,
+		Year:         s.Year,
+		Competitions: competitions.Competitions,
+		CreatedAt:    fromDbToProtoTimestamp(s.CreatedAt),
+		UpdatedAt:    fromDbToProtoTimestamp(s.UpdatedAt),
+	}, nil
+}
+
+func FromCreateRequestToInsertSeason(r *CreateSeasonRequest) db.InsertSeasonParams {
+	return db.InsertSeasonParams{
+		Name: r.Name,
+		Year: r.Year,
+	}
+}
+
+func FromUpdateRequestToUpdateSeason(r *UpdateSeasonRequest) (db.UpdateSeasonParams, error) {
+	id, err := FromProtoToDbId(r.Id)
+	if err != nil {
+		return db.UpdateSeasonParams{}, NewRequestBindingError(err)
+	}
+
+	return db.UpdateSeasonParams{
+		ID:   id,
+		Name: r.Name,
+		Year: r.Year,
+	}, nil
+}
+
+// FromDbSeasonStatsToResponse returns the stats of the users within a
+// season, the rating bias is relative to the average of the season.
+func FromDbSeasonStatsToResponse(stats []db.ComputeUserStatsBySeasonIdRow, seasonStats db.ComputeGlobalStatsBySeasonIdRow, users []db.User) (*pb.ListUserStatsResponse, error) {
+	userStats := make([]db.UserStat, len(stats))
+	for i, stat := range stats {
+		userStats[i] = db.UserStat{
+			UserID:      stat.UserID,
+			RatingAvg:   stat.RatingAvg,
+			RatingCount: fromInt32ToInt4(stat.RatingCount),
+		}
+	}
+
+	globalStats := db.GlobalStat{
+		RatingAvg:   seasonStats.RatingAvg,
+		RatingCount: fromInt32ToInt4(seasonStats.RatingCount),
+	}
+
+	return FromDbUserStatListToResponse(userStats, globalStats, users)
+}
+
+func FromDbSemiFinalActsToProgressionResponse(seasonId pgtype.UUID, a []db.ListSemiFinalActsBySeasonIdRow) (*SeasonProgressionResponse, error) {
+	id, err := FromDbToProtoId(seasonId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	response := &SeasonProgressionResponse{
+		SeasonId: id,
+		Heats:    make([]*HeatProgressionResponse, 0),
+	}
+
+	heats := make(map[pgtype.UUID]*HeatProgressionResponse)
+	for _, act := range a {
+		heat, ok := heats[act.CompetitionID]
+		if !ok {
+			competitionId, err := FromDbToProtoId(act.CompetitionID)
+			if err != nil {
+				return nil, NewResponseBindingError(err)
+			}
+
+			heat = &HeatProgressionResponse{
+				CompetitionId: competitionId,
+				Qualified:     make([]*pb.ActResponse, 0),
+				Eliminated:    make([]*pb.ActResponse, 0),
+			}
+			heats[act.CompetitionID] = heat
+			response.Heats = append(response.Heats, heat)
+		}
+
+		actResponse, err := FromDbActToResponse(act.Act, make([]db.Rating, 0), make([]db.User, 0))
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		if act.Qualified {
+			heat.Qualified = append(heat.Qualified, actResponse)
+		} else {
+			heat.Eliminated = append(heat.Eliminated, actResponse)
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,120 @@
+package mapper
+
+import (
+	"fmt"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
+)
+
+type UserSimilarityResponse struct {
+	User            *pb.UserResponse `json:"user"`
+	Correlation     float64          `json:"correlation"`
+	OverlappingActs int32            `json:"overlapping_acts"`
+}
+
+type ListSimilarUsersResponse struct {
+	MostSimilar  []*UserSimilarityResponse `json:"most_similar"`
+	LeastSimilar []*UserSimilarityResponse `json:"least_similar"`
+}
+
+type ListSimilarUsersRequest struct {
+	Limit      int32 `query:"limit"`
+	MinOverlap int32 `query:"minOverlap"`
+}
+
+// FromDbRatingsToTotalsByAct returns the totals of the ratings keyed by
+// competition and act so that the same act in different competitions is
+// compared separately.
+func FromDbRatingsToTotalsByAct(r []db.Rating) map[string]float64 {
+	totals := make(map[string]float64, len(r))
+	for _, rating := range r {
+		totals[fromDbRatingToActKey(rating)] = float64(rating.Total)
+	}
+
+	return totals
+}
+
+func FromDbRatingsToTotalsByUserAndAct(r []db.Rating) (map[string]map[string]float64, error) {
+	totals := make(map[string]map[string]float64)
+	for _, rating := range r {
+		userId, err := FromDbToProtoId(rating.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := totals[userId]; !ok {
+			totals[userId] = make(map[string]float64)
+		}
+
+		totals[userId][fromDbRatingToActKey(rating)] = float64(rating.Total)
+	}
+
+	return totals, nil
+}
+
+func fromDbRatingToActKey(rating db.Rating) string {
+	return fmt.Sprintf("%x/%x", rating.CompetitionID.Bytes, rating.ActID.Bytes)
+}
+
+// FromSimilaritiesToResponse returns up to limit of the most and of the
+// least similar users. The similarities have to be ordered from the most
+// to the least similar user, users with a positive correlation are never
+// listed as least similar and vice versa.
+func FromSimilaritiesToResponse(similarities []util.Similarity, users []db.User, limit int) (*ListSimilarUsersResponse, error) {
+	response := &ListSimilarUsersResponse{
+		MostSimilar:  make([]*UserSimilarityResponse, 0, limit),
+		LeastSimilar: make([]*UserSimilarityResponse, 0, limit),
+	}
+
+	for _, similarity := range similarities {
+		if len(response.MostSimilar) >= limit || similarity.Correlation <= 0 {
+			break
+		}
+
+		similarityResponse, err := fromSimilarityToResponse(similarity, users)
+		if err != nil {
+			return nil, err
+		}
+
+		response.MostSimilar = append(response.MostSimilar, similarityResponse)
+	}
+
+	for i := len(similarities) - 1; i >= 0; i-- {
+		similarity := similarities[i]
+		if len(response.LeastSimilar) >= limit || similarity.Correlation >= 0 {
+			break
+		}
+
+		similarityResponse, err := fromSimilarityToResponse(similarity, users)
+		if err != nil {
+			return nil, err
+		}
+
+		response.LeastSimilar = append(response.LeastSimilar, similarityResponse)
+	}
+
+	return response, nil
+}
+
+func fromSimilarityToResponse(similarity util.Similarity, users []db.User) (*UserSimilarityResponse, error) {
+	userId, err := FromProtoToDbId(similarity.UserId)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	var userResponse *pb.UserResponse
+	if user := getUser(users, userId); user != nil {
+		userResponse, err = FromDbUserToResponse(*user)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+	}
+
+	return &UserSimilarityResponse{
+		User:            userResponse,
+		Correlation:     similarity.Correlation,
+		OverlappingActs: similarity.Overlap,
+	}, nil
+}
@@ -1,9 +1,14 @@
 package mapper
 
 import (
+	"fmt"
+	"strings"
+
 	pb "github.com/hyperremix/song-contest-rater-protos/v3"
 	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/util"
 	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func EmptyUserStatsResponse() *pb.UserStatsResponse {
@@ -101,26 +106,6 @@ func FromDbGlobalStatsToResponse(stats db.GlobalStat) (*pb.GlobalStatsResponse,
 	}, nil
 }
 
-func FromRatingToDbUserStats(rating *pb.RatingResponse) db.UpsertUserStatsParams {
-	userId, err := FromProtoToDbId(rating.User.Id)
-	if err != nil {
-		return db.UpsertUserStatsParams{}
-	}
-
-	return db.UpsertUserStatsParams{
-		UserID:      userId,
-		RatingAvg:   fromFloat64ToNumeric(float64(rating.Total)),
-		RatingCount: fromInt32ToInt4(1),
-	}
-}
-
-func FromRatingToDbGlobalStats(rating *pb.RatingResponse) db.UpsertGlobalStatsParams {
-	return db.UpsertGlobalStatsParams{
-		RatingAvg:   fromFloat64ToNumeric(float64(rating.Total)),
-		RatingCount: fromInt32ToInt4(1),
-	}
-}
-
 func AddToUserStats(newRating *pb.RatingResponse, userStats db.UserStat) (db.UpsertUserStatsParams, error) {
 	newAvg, newCount, err := calculateAddedAverage(
 		userStats.RatingAvg,
@@ -173,65 +158,34 @@ func RemoveFromUserStats(rating *pb.RatingResponse, userStats db.UserStat) (db.U
 	}, nil
 }
 
-func AddToGlobalStats(newRating *pb.RatingResponse, globalStats db.GlobalStat) (db.UpsertGlobalStatsParams, error) {
-	newAvg, newCount, err := calculateAddedAverage(
-		globalStats.RatingAvg,
-		globalStats.RatingCount.Int32,
-		float64(newRating.Total),
-	)
-	if err != nil {
-		return db.UpsertGlobalStatsParams{}, err
-	}
-
-	return db.UpsertGlobalStatsParams{
-		RatingAvg:   newAvg,
-		RatingCount: newCount,
-	}, nil
-}
-
-func UpdateGlobalStats(newRating *pb.RatingResponse, oldRating *pb.RatingResponse, globalStats db.GlobalStat) (db.UpsertGlobalStatsParams, error) {
-	newAvg, err := calculateUpdatedAverage(
-		globalStats.RatingAvg,
-		globalStats.RatingCount.Int32,
-		float64(oldRating.Total),
-		float64(newRating.Total),
-	)
-	if err != nil {
-		return db.UpsertGlobalStatsParams{}, err
-	}
-
-	return db.UpsertGlobalStatsParams{
-		RatingAvg:   newAvg,
-		RatingCount: globalStats.RatingCount,
-	}, nil
+func fromRatingBiasToCriticType(ratingBias float64) pb.CriticType {
+	return fromScaledRatingBiasToCriticType(ratingBias, 1)
 }
 
-func RemoveFromGlobalStats(rating *pb.RatingResponse, globalStats db.GlobalStat) (db.UpsertGlobalStatsParams, error) {
-	newAvg, newCount, err := calculateRemovedAverage(
-		globalStats.RatingAvg,
-		globalStats.RatingCount.Int32,
-		float64(rating.Total),
-	)
-	if err != nil {
-		return db.UpsertGlobalStatsParams{}, err
+// fromCategoryBiasToCriticType classifies the bias of a single category. The
+// thresholds of the rating total are scaled down to the share a category has
+// in the default total and to the range of the category relative to the
+// range of the default categories.
+func fromCategoryBiasToCriticType(categoryBias float64, min int32, max int32) pb.CriticType {
+	defaultCategory := util.DefaultCategories[0]
+	scale := 1 / float64(len(util.DefaultCategories))
+	if max > min {
+		scale *= float64(max-min) / float64(defaultCategory.Max-defaultCategory.Min)
 	}
 
-	return db.UpsertGlobalStatsParams{
-		RatingAvg:   newAvg,
-		RatingCount: newCount,
-	}, nil
+	return fromScaledRatingBiasToCriticType(categoryBias, scale)
 }
 
-func fromRatingBiasToCriticType(ratingBias float64) pb.CriticType {
+func fromScaledRatingBiasToCriticType(ratingBias float64, scale float64) pb.CriticType {
 	switch {
-	case ratingBias <= -6.0:
+	case ratingBias <= -6.0*scale:
 		return pb.CriticType_CRITIC_TYPE_HARSH
-	case ratingBias <= -2.0:
+	case ratingBias <= -2.0*scale:
 		return pb.CriticType_CRITIC_TYPE_SLIGHTLY_CRITICAL
-	case ratingBias >= 2.0:
-		return pb.CriticType_CRITIC_TYPE_EASY_TO_PLEASE
-	case ratingBias >= 6.0:
+	case ratingBias >= 6.0*scale:
 		return pb.CriticType_CRITIC_TYPE_GENEROUS
+	case ratingBias >= 2.0*scale:
+		return pb.CriticType_CRITIC_TYPE_EASY_TO_PLEASE
 	default:
 		return pb.CriticType_CRITIC_TYPE_BALANCED
 	}
@@ -266,3 +220,148 @@ func calculateRemovedAverage(dbCurrentAvg pgtype.Numeric, count int32, valueToRe
 	newCount := count - 1
 	return fromFloat64ToNumeric((currentAvg*float64(count) - valueToRemove) / float64(newCount)), fromInt32ToInt4(newCount), nil
 }
+
+type RecomputeStatsRequest struct {
+	DryRun bool `query:"dryRun"`
+}
+
+type UserStatsHistoryEntryResponse struct {
+	Date          *timestamppb.Timestamp `json:"date"`
+	UserRatingAvg float64                `json:"user_rating_avg"`
+	TotalRatings  int32                  `json:"total_ratings"`
+	RatingBias    float64                `json:"rating_bias"`
+	CriticType    pb.CriticType          `json:"critic_type"`
+}
+
+type UserStatsHistoryResponse struct {
+	User    *pb.UserResponse                 `json:"user"`
+	Entries []*UserStatsHistoryEntryResponse `json:"entries"`
+}
+
+func FromDbUserStatsSnapshotsToHistoryResponse(s []db.UserStatsSnapshot, user db.User) (*UserStatsHistoryResponse, error) {
+	userResponse, err := FromDbUserToResponse(user)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	entries := make([]*UserStatsHistoryEntryResponse, len(s))
+	for i, snapshot := range s {
+		userRatingAvg, err := fromNumericToFloat64(snapshot.RatingAvg)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		globalRatingAvg, err := fromNumericToFloat64(snapshot.GlobalRatingAvg)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		entries[i] = &UserStatsHistoryEntryResponse{
+			Date:          timestamppb.New(snapshot.SnapshotDate.Time),
+			UserRatingAvg: userRatingAvg,
+			TotalRatings:  snapshot.RatingCount.Int32,
+			RatingBias:    userRatingAvg - globalRatingAvg,
+			CriticType:    fromRatingBiasToCriticType(userRatingAvg - globalRatingAvg),
+		}
+	}
+
+	return &UserStatsHistoryResponse{
+		User:    userResponse,
+		Entries: entries,
+	}, nil
+}
+
+type CategoryBiasResponse struct {
+	Category       string        `json:"category"`
+	Min            int32         `json:"min"`
+	Max            int32         `json:"max"`
+	UserScoreAvg   float64       `json:"user_score_avg"`
+	GlobalScoreAvg float64       `json:"global_score_avg"`
+	TotalScores    int32         `json:"total_scores"`
+	CategoryBias   float64       `json:"category_bias"`
+	CriticType     pb.CriticType `json:"critic_type"`
+}
+
+type CriticProfileResponse struct {
+	User       *pb.UserResponse        `json:"user"`
+	Stats      *pb.UserStatsResponse   `json:"stats"`
+	Categories []*CategoryBiasResponse `json:"categories"`
+	Summary    string                  `json:"summary"`
+}
+
+// FromDbToCriticProfileResponse compares the user's average score of every
+// category with the global average score of the category with the same
+// range, categories with the same name but another range are compared
+// separately. The summary names
+// the categories the user is not balanced on, e.g. "harsh on looks,
+// generous on singing".
+func FromDbToCriticProfileResponse(userStats *pb.UserStatsResponse, u db.User, userCategoryStats []db.UserCategoryStat, globalCategoryStats []db.GlobalCategoryStat) (*CriticProfileResponse, error) {
+	userResponse, err := FromDbUserToResponse(u)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	type categoryKey struct {
+		category string
+		min, max int32
+	}
+
+	globalAvgs := make(map[categoryKey]float64, len(globalCategoryStats))
+	for _, stats := range globalCategoryStats {
+		if stats.ScoreCount > 0 {
+			globalAvgs[categoryKey{stats.Category, stats.Min, stats.Max}] = float64(stats.ScoreSum) / float64(stats.ScoreCount)
+		}
+	}
+
+	ranges := make(map[string]int, len(userCategoryStats))
+	for _, stats := range userCategoryStats {
+		ranges[stats.Category]++
+	}
+
+	categories := make([]*CategoryBiasResponse, 0, len(userCategoryStats))
+	var summary []string
+	for _, stats := range userCategoryStats {
+		globalAvg, ok := globalAvgs[categoryKey{stats.Category, stats.Min, stats.Max}]
+		if !ok || stats.ScoreCount == 0 {
+			continue
+		}
+
+		userAvg := float64(stats.ScoreSum) / float64(stats.ScoreCount)
+		criticType := fromCategoryBiasToCriticType(userAvg-globalAvg, stats.Min, stats.Max)
+
+		categories = append(categories, &CategoryBiasResponse{
+			Category:       stats.Category,
+			Min:            stats.Min,
+			Max:            stats.Max,
+			UserScoreAvg:   userAvg,
+			GlobalScoreAvg: globalAvg,
+			TotalScores:    stats.ScoreCount,
+			CategoryBias:   userAvg - globalAvg,
+			CriticType:     criticType,
+		})
+
+		if description, ok := criticTypeDescriptions[criticType]; ok {
+			category := stats.Category
+			if ranges[stats.Category] > 1 {
+				category = fmt.Sprintf("%s (%d-%d)", stats.Category, stats.Min, stats.Max)
+			}
+
+			summary = append(summary, description+" on "+category)
+		}
+	}
+
+	userStats.User = userResponse
+	return &CriticProfileResponse{
+		User:       userResponse,
+		Stats:      userStats,
+		Categories: categories,
+		Summary:    strings.Join(summary, ", "),
+	}, nil
+}
+
+var criticTypeDescriptions = map[pb.CriticType]string{
+	pb.CriticType_CRITIC_TYPE_HARSH:             "harsh",
+	pb.CriticType_CRITIC_TYPE_SLIGHTLY_CRITICAL: "slightly critical",
+	pb.CriticType_CRITIC_TYPE_EASY_TO_PLEASE:    "easy to please",
+	pb.CriticType_CRITIC_TYPE_GENEROUS:          "generous",
+}
@@ -0,0 +1,140 @@
+package mapper
+
+import (
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type UserErasureResponse struct {
+	Id                string                 `json:"id"`
+	UserId            string                 `json:"user_id"`
+	RequestedBy       string                 `json:"requested_by"`
+	DeletedRatings    int32                  `json:"deleted_ratings"`
+	TransferredGroups int32                  `json:"transferred_groups"`
+	DeletedGroups     int32                  `json:"deleted_groups"`
+	DeletedImages     int32                  `json:"deleted_images"`
+	ClerkUnlinked     bool                   `json:"clerk_unlinked"`
+	Error             string                 `json:"error,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `json:"created_at"`
+	CompletedAt       *timestamppb.Timestamp `json:"completed_at,omitempty"`
+}
+
+type ListUserErasuresResponse struct {
+	Erasures []*UserErasureResponse `json:"erasures"`
+}
+
+func FromDbUserErasureListToResponse(e []db.UserErasure) (*ListUserErasuresResponse, error) {
+	erasures := make([]*UserErasureResponse, 0, len(e))
+
+	for _, erasure := range e {
+		response, err := FromDbUserErasureToResponse(erasure)
+		if err != nil {
+			return nil, NewResponseBindingError(err)
+		}
+
+		erasures = append(erasures, response)
+	}
+
+	return &ListUserErasuresResponse{Erasures: erasures}, nil
+}
+
+func FromDbUserErasureToResponse(e db.UserErasure) (*UserErasureResponse, error) {
+	id, err := FromDbToProtoId(e.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	userId, err := FromDbToProtoId(e.UserID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	requestedBy, err := FromDbToProtoId(e.RequestedBy)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	var completedAt *timestamppb.Timestamp
+	if e.CompletedAt.Valid {
+		completedAt = fromDbToProtoTimestamp(e.CompletedAt)
+	}
+
+	return &UserErasureResponse{
+		Id:                id,
+		UserId:            userId,
+		RequestedBy:       requestedBy,
+		DeletedRatings:    e.DeletedRatings,
+		TransferredGroups: e.TransferredGroups,
+		DeletedGroups:     e.DeletedGroups,
+		DeletedImages:     e.DeletedImages,
+		ClerkUnlinked:     e.ClerkUnlinked,
+		Error:             e.Error.String,
+		CreatedAt:         fromDbToProtoTimestamp(e.CreatedAt),
+		CompletedAt:       completedAt,
+	}, nil
+}
+
+// FromErasureResultToCompleteUserErasure records the outcome of the steps
+// of an erasure that run outside of the database.
+func FromErasureResultToCompleteUserErasure(id pgtype.UUID, deletedImages int, clerkUnlinked bool, err error) db.CompleteUserErasureParams {
+	params := db.CompleteUserErasureParams{
+		ID:            id,
+		DeletedImages: int32(deletedImages),
+		ClerkUnlinked: clerkUnlinked,
+	}
+
+	if err != nil {
+		params.Error = pgtype.Text{String: err.Error(), Valid: true}
+	}
+
+	return params
+}
+
+// FromDbRankingPredictionsToResponses splits the ranking predictions of a
+// user, which are ordered by competition, into one response per
+// competition.
+func FromDbRankingPredictionsToResponses(p []db.RankingPrediction) ([]*RankingPredictionResponse, error) {
+	responses := make([]*RankingPredictionResponse, 0)
+
+	for start := 0; start < len(p); {
+		end := start
+		for end < len(p) && p[end].CompetitionID == p[start].CompetitionID {
+			end++
+		}
+
+		response, err := FromDbRankingPredictionsToResponse(p[start].CompetitionID, p[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, response)
+		start = end
+	}
+
+	return responses, nil
+}
+
+// FromDbQualificationPredictionsToResponses splits the qualification
+// predictions of a user, which are ordered by competition, into one
+// response per competition.
+func FromDbQualificationPredictionsToResponses(p []db.QualificationPrediction) ([]*QualificationPredictionResponse, error) {
+	responses := make([]*QualificationPredictionResponse, 0)
+
+	for start := 0; start < len(p); {
+		end := start
+		for end < len(p) && p[end].CompetitionID == p[start].CompetitionID {
+			end++
+		}
+
+		response, err := FromDbToQualificationPredictionResponse(p[start].CompetitionID, p[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, response)
+		start = end
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,54 @@
+package mapper
+
+import (
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type VotingResponse struct {
+	CompetitionId  string                 `json:"competition_id"`
+	IsOpen         bool                   `json:"is_open"`
+	VotingClosesAt *timestamppb.Timestamp `json:"voting_closes_at,omitempty"`
+	LockedAt       *timestamppb.Timestamp `json:"locked_at,omitempty"`
+}
+
+type UpdateVotingWindowRequest struct {
+	Id             string                 `param:"id"`
+	VotingClosesAt *timestamppb.Timestamp `json:"voting_closes_at"`
+}
+
+func FromDbToVotingResponse(c db.Competition, isOpen bool) (*VotingResponse, error) {
+	competitionId, err := FromDbToProtoId(c.ID)
+	if err != nil {
+		return nil, NewResponseBindingError(err)
+	}
+
+	response := &VotingResponse{
+		CompetitionId: competitionId,
+		IsOpen:        isOpen,
+	}
+
+	if c.VotingClosesAt.Valid {
+		response.VotingClosesAt = fromDbToProtoTimestamp(c.VotingClosesAt)
+	}
+
+	if c.LockedAt.Valid {
+		response.LockedAt = fromDbToProtoTimestamp(c.LockedAt)
+	}
+
+	return response, nil
+}
+
+func FromUpdateRequestToUpdateVotingClosesAt(request *UpdateVotingWindowRequest) (db.UpdateCompetitionVotingClosesAtParams, error) {
+	id, err := FromProtoToDbId(request.Id)
+	if err != nil {
+		return db.UpdateCompetitionVotingClosesAtParams{}, NewRequestBindingError(err)
+	}
+
+	params := db.UpdateCompetitionVotingClosesAtParams{ID: id}
+	if request.VotingClosesAt != nil {
+		params.VotingClosesAt = fromProtoToDbTimestamp(request.VotingClosesAt)
+	}
+
+	return params, nil
+}
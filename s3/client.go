@@ -41,13 +41,15 @@ func New() *Client {
 	}
 }
 
+const profilePicturesPrefix = "profile-pictures/"
+
 type GetPresignedUrlResponse struct {
 	PresignedURL string `json:"presigned_url"`
 	ImageURL     string `json:"image_url"`
 }
 
 func (c *Client) GetPresignedURL(ctx context.Context, fileName, contentType string) (GetPresignedUrlResponse, error) {
-	completeFileName := fmt.Sprintf("profile-pictures/%s", fileName)
+	completeFileName := profilePicturesPrefix + fileName
 
 	req, _ := c.s3Client.PutObjectRequest(&s3.PutObjectInput{
 		Bucket:      aws.String(c.bucketName),
@@ -65,3 +67,35 @@ func (c *Client) GetPresignedURL(ctx context.Context, fileName, contentType stri
 		ImageURL:     fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.bucketName, c.region, completeFileName),
 	}, nil
 }
+
+// DeleteProfilePictures deletes every profile picture uploaded by the
+// user. The file names of profile pictures start with the id of the user
+// they belong to.
+func (c *Client) DeleteProfilePictures(ctx context.Context, userId string) (int, error) {
+	var deleted int
+	var deleteErr error
+
+	err := c.s3Client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucketName),
+		Prefix: aws.String(profilePicturesPrefix + userId),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			_, deleteErr = c.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(c.bucketName),
+				Key:    object.Key,
+			})
+			if deleteErr != nil {
+				return false
+			}
+
+			deleted++
+		}
+
+		return true
+	})
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, deleteErr
+}
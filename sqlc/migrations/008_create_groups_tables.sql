@@ -0,0 +1,24 @@
+CREATE TABLE groups (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    invite_code TEXT NOT NULL,
+    owner_id uuid NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (owner_id) REFERENCES users(id),
+    UNIQUE (invite_code)
+);
+
+CREATE TABLE groups_users (
+    group_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id),
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS groups_users;
+DROP TABLE IF EXISTS groups;
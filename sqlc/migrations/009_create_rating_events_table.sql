@@ -0,0 +1,11 @@
+CREATE TABLE rating_events (
+    id BIGSERIAL PRIMARY KEY,
+    event TEXT NOT NULL,
+    user_id uuid NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS rating_events;
@@ -0,0 +1,18 @@
+ALTER TABLE rating_events ADD COLUMN competition_id uuid;
+ALTER TABLE rating_events ADD COLUMN act_id uuid;
+
+UPDATE rating_events
+SET
+    competition_id = (data->>'competition_id')::uuid,
+    act_id = (data->>'act_id')::uuid;
+
+ALTER TABLE rating_events ALTER COLUMN competition_id SET NOT NULL;
+ALTER TABLE rating_events ALTER COLUMN act_id SET NOT NULL;
+
+CREATE INDEX rating_events_competition_id_act_id_idx ON rating_events (competition_id, act_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS rating_events_competition_id_act_id_idx;
+ALTER TABLE rating_events DROP COLUMN act_id;
+ALTER TABLE rating_events DROP COLUMN competition_id;
@@ -0,0 +1,15 @@
+CREATE TYPE LIVE_STATE AS ENUM (
+    'LIVE_STATE_PENDING',
+    'LIVE_STATE_PERFORMING',
+    'LIVE_STATE_CLOSED'
+);
+ALTER TABLE competitions ADD COLUMN live_state LIVE_STATE NOT NULL DEFAULT 'LIVE_STATE_PENDING';
+ALTER TABLE competitions ADD COLUMN live_act_order INT;
+ALTER TABLE competitions ADD COLUMN performed_acts_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+---- create above / drop below ----
+
+ALTER TABLE competitions DROP COLUMN performed_acts_only;
+ALTER TABLE competitions DROP COLUMN live_act_order;
+ALTER TABLE competitions DROP COLUMN live_state;
+DROP TYPE LIVE_STATE;
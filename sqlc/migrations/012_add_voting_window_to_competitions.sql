@@ -0,0 +1,14 @@
+ALTER TABLE competitions ADD COLUMN voting_closes_at TIMESTAMPTZ;
+ALTER TABLE competitions ADD COLUMN locked_at TIMESTAMPTZ;
+
+CREATE TABLE competition_snapshots (
+    competition_id UUID PRIMARY KEY REFERENCES competitions(id) ON DELETE CASCADE,
+    scoreboard JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+---- create above / drop below ----
+
+DROP TABLE competition_snapshots;
+ALTER TABLE competitions DROP COLUMN locked_at;
+ALTER TABLE competitions DROP COLUMN voting_closes_at;
@@ -0,0 +1,77 @@
+CREATE TABLE competition_categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    competition_id UUID NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    min INT NOT NULL,
+    max INT NOT NULL,
+    weight INT NOT NULL DEFAULT 1,
+    position INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (competition_id, name),
+    CONSTRAINT check_min_max CHECK (min <= max),
+    CONSTRAINT check_weight_positive CHECK (weight >= 1)
+);
+
+INSERT INTO competition_categories (competition_id, name, min, max, weight, position)
+SELECT c.id, d.name, 1, 15, 1, d.position
+FROM competitions c
+CROSS JOIN (VALUES ('song', 1), ('singing', 2), ('show', 3), ('looks', 4), ('clothes', 5)) AS d(name, position);
+
+CREATE TABLE rating_scores (
+    rating_id UUID NOT NULL REFERENCES ratings(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES competition_categories(id) ON DELETE CASCADE,
+    score INT NOT NULL,
+    PRIMARY KEY (rating_id, category_id)
+);
+
+INSERT INTO rating_scores (rating_id, category_id, score)
+SELECT r.id, cc.id, s.score
+FROM ratings r
+CROSS JOIN LATERAL (VALUES ('song', r.song), ('singing', r.singing), ('show', r."show"), ('looks', r.looks), ('clothes', r.clothes)) AS s(name, score)
+JOIN competition_categories cc ON cc.competition_id = r.competition_id AND cc.name = s.name
+WHERE s.score IS NOT NULL;
+
+ALTER TABLE ratings DROP CONSTRAINT ratings_song_check;
+ALTER TABLE ratings DROP CONSTRAINT ratings_singing_check;
+ALTER TABLE ratings DROP CONSTRAINT ratings_show_check;
+ALTER TABLE ratings DROP CONSTRAINT ratings_looks_check;
+ALTER TABLE ratings DROP CONSTRAINT ratings_clothes_check;
+
+ALTER TABLE ratings DROP COLUMN total;
+ALTER TABLE ratings ADD COLUMN total INT NOT NULL DEFAULT 0;
+UPDATE ratings SET total = COALESCE(song, 0) + COALESCE(singing, 0) + COALESCE("show", 0) + COALESCE(looks, 0) + COALESCE(clothes, 0);
+
+ALTER TABLE user_stats ALTER COLUMN rating_avg TYPE DECIMAL(6,2);
+ALTER TABLE global_stats ALTER COLUMN rating_avg TYPE DECIMAL(6,2);
+
+---- create above / drop below ----
+
+-- Competitions with custom categories can have scores and averages outside
+-- of the fixed range, they are clamped into it so that the checks can be
+-- added again. The stats have to be recomputed afterwards.
+UPDATE global_stats SET rating_avg = LEAST(rating_avg, 75);
+UPDATE user_stats SET rating_avg = LEAST(rating_avg, 75);
+
+ALTER TABLE global_stats ALTER COLUMN rating_avg TYPE DECIMAL(4,2);
+ALTER TABLE user_stats ALTER COLUMN rating_avg TYPE DECIMAL(4,2);
+
+UPDATE ratings
+SET
+    song = LEAST(GREATEST(song, 1), 15),
+    singing = LEAST(GREATEST(singing, 1), 15),
+    "show" = LEAST(GREATEST("show", 1), 15),
+    looks = LEAST(GREATEST(looks, 1), 15),
+    clothes = LEAST(GREATEST(clothes, 1), 15);
+
+ALTER TABLE ratings DROP COLUMN total;
+ALTER TABLE ratings ADD COLUMN total INT GENERATED ALWAYS AS (song + singing + "show" + looks + clothes) STORED;
+
+ALTER TABLE ratings ADD CONSTRAINT ratings_song_check CHECK (song BETWEEN 1 AND 15);
+ALTER TABLE ratings ADD CONSTRAINT ratings_singing_check CHECK (singing BETWEEN 1 AND 15);
+ALTER TABLE ratings ADD CONSTRAINT ratings_show_check CHECK ("show" BETWEEN 1 AND 15);
+ALTER TABLE ratings ADD CONSTRAINT ratings_looks_check CHECK (looks BETWEEN 1 AND 15);
+ALTER TABLE ratings ADD CONSTRAINT ratings_clothes_check CHECK (clothes BETWEEN 1 AND 15);
+
+DROP TABLE rating_scores;
+DROP TABLE competition_categories;
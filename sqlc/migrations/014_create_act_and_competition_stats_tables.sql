@@ -0,0 +1,56 @@
+-- The stats of the weighted rating totals are stored with an empty category.
+CREATE TABLE act_stats (
+    competition_id UUID NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    act_id UUID NOT NULL REFERENCES acts(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    rating_count INT NOT NULL,
+    rating_avg DECIMAL(8,2) NOT NULL,
+    rating_variance DECIMAL(12,2) NOT NULL,
+    rating_min INT NOT NULL,
+    rating_max INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (competition_id, act_id, category)
+);
+
+CREATE TABLE competition_stats (
+    competition_id UUID NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    rating_count INT NOT NULL,
+    rating_avg DECIMAL(8,2) NOT NULL,
+    rating_variance DECIMAL(12,2) NOT NULL,
+    rating_min INT NOT NULL,
+    rating_max INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (competition_id, category)
+);
+
+CREATE INDEX act_stats_act_id_idx ON act_stats (act_id);
+
+INSERT INTO act_stats (competition_id, act_id, category, rating_count, rating_avg, rating_variance, rating_min, rating_max)
+SELECT r.competition_id, r.act_id, cc.name, COUNT(*), AVG(rs.score), VAR_POP(rs.score), MIN(rs.score), MAX(rs.score)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+GROUP BY r.competition_id, r.act_id, cc.name
+UNION ALL
+SELECT r.competition_id, r.act_id, '', COUNT(*), AVG(r.total), VAR_POP(r.total), MIN(r.total), MAX(r.total)
+FROM ratings r
+GROUP BY r.competition_id, r.act_id;
+
+INSERT INTO competition_stats (competition_id, category, rating_count, rating_avg, rating_variance, rating_min, rating_max)
+SELECT r.competition_id, cc.name, COUNT(*), AVG(rs.score), VAR_POP(rs.score), MIN(rs.score), MAX(rs.score)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+GROUP BY r.competition_id, cc.name
+UNION ALL
+SELECT r.competition_id, '', COUNT(*), AVG(r.total), VAR_POP(r.total), MIN(r.total), MAX(r.total)
+FROM ratings r
+GROUP BY r.competition_id;
+
+---- create above / drop below ----
+
+DROP TABLE competition_stats;
+DROP TABLE act_stats;
@@ -0,0 +1,19 @@
+CREATE TABLE user_stats_snapshots (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    rating_avg DECIMAL(6,2),
+    rating_count INT,
+    global_rating_avg DECIMAL(6,2),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, snapshot_date)
+);
+
+INSERT INTO user_stats_snapshots (user_id, snapshot_date, rating_avg, rating_count, global_rating_avg)
+SELECT us.user_id, CURRENT_DATE, us.rating_avg, us.rating_count, gs.rating_avg
+FROM user_stats us
+LEFT JOIN global_stats gs ON gs.id;
+
+---- create above / drop below ----
+
+DROP TABLE user_stats_snapshots;
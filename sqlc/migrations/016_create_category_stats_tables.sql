@@ -0,0 +1,35 @@
+CREATE TABLE user_category_stats (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    score_sum BIGINT NOT NULL,
+    score_count INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category)
+);
+
+CREATE TABLE global_category_stats (
+    category TEXT PRIMARY KEY,
+    score_sum BIGINT NOT NULL,
+    score_count INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO user_category_stats (user_id, category, score_sum, score_count)
+SELECT r.user_id, cc.name, SUM(rs.score), COUNT(*)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+GROUP BY r.user_id, cc.name;
+
+INSERT INTO global_category_stats (category, score_sum, score_count)
+SELECT cc.name, SUM(rs.score), COUNT(*)
+FROM rating_scores rs
+JOIN competition_categories cc ON cc.id = rs.category_id
+GROUP BY cc.name;
+
+---- create above / drop below ----
+
+DROP TABLE global_category_stats;
+DROP TABLE user_category_stats;
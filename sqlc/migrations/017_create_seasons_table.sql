@@ -0,0 +1,27 @@
+CREATE TABLE seasons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    year INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE competitions ADD COLUMN season_id UUID REFERENCES seasons(id) ON DELETE SET NULL;
+
+CREATE INDEX competitions_season_id_idx ON competitions (season_id);
+
+-- Existing competitions are grouped into one season per contest year.
+INSERT INTO seasons (name, year)
+SELECT DISTINCT EXTRACT(YEAR FROM start_time)::INT::TEXT, EXTRACT(YEAR FROM start_time)::INT
+FROM competitions;
+
+UPDATE competitions c
+SET season_id = s.id
+FROM seasons s
+WHERE s.year = EXTRACT(YEAR FROM c.start_time)::INT;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS competitions_season_id_idx;
+ALTER TABLE competitions DROP COLUMN season_id;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,26 @@
+-- An act of a competition that qualified into a later competition.
+CREATE TABLE qualifications (
+    competition_id UUID NOT NULL,
+    act_id UUID NOT NULL,
+    target_competition_id UUID NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (competition_id, act_id),
+    FOREIGN KEY (competition_id, act_id) REFERENCES competitions_acts(competition_id, act_id) ON DELETE CASCADE,
+    CHECK (competition_id <> target_competition_id)
+);
+
+CREATE TABLE qualification_predictions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    competition_id UUID NOT NULL,
+    act_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, competition_id, act_id),
+    FOREIGN KEY (competition_id, act_id) REFERENCES competitions_acts(competition_id, act_id) ON DELETE CASCADE
+);
+
+CREATE INDEX qualification_predictions_competition_id_idx ON qualification_predictions (competition_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS qualification_predictions;
+DROP TABLE IF EXISTS qualifications;
@@ -0,0 +1,28 @@
+CREATE TABLE competition_results (
+    competition_id UUID NOT NULL,
+    act_id UUID NOT NULL,
+    placement INT NOT NULL CHECK (placement > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (competition_id, act_id),
+    FOREIGN KEY (competition_id, act_id) REFERENCES competitions_acts(competition_id, act_id) ON DELETE CASCADE,
+    UNIQUE (competition_id, placement)
+);
+
+CREATE TABLE ranking_predictions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    competition_id UUID NOT NULL,
+    act_id UUID NOT NULL,
+    position INT NOT NULL CHECK (position > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, competition_id, act_id),
+    FOREIGN KEY (competition_id, act_id) REFERENCES competitions_acts(competition_id, act_id) ON DELETE CASCADE,
+    UNIQUE (user_id, competition_id, position)
+);
+
+CREATE INDEX ranking_predictions_competition_id_idx ON ranking_predictions (competition_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS ranking_predictions;
+DROP TABLE IF EXISTS competition_results;
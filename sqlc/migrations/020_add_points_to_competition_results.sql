@@ -0,0 +1,9 @@
+ALTER TABLE competition_results ADD COLUMN points INT;
+ALTER TABLE competition_results ADD COLUMN jury_points INT;
+ALTER TABLE competition_results ADD COLUMN televote_points INT;
+
+---- create above / drop below ----
+
+ALTER TABLE competition_results DROP COLUMN televote_points;
+ALTER TABLE competition_results DROP COLUMN jury_points;
+ALTER TABLE competition_results DROP COLUMN points;
@@ -0,0 +1,22 @@
+-- The audit trail of erased users. It references neither the erased user
+-- nor the requesting user, both may no longer exist, and holds no
+-- personal data apart from the ids.
+CREATE TABLE user_erasures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    requested_by UUID NOT NULL,
+    deleted_ratings INT NOT NULL DEFAULT 0,
+    transferred_groups INT NOT NULL DEFAULT 0,
+    deleted_groups INT NOT NULL DEFAULT 0,
+    deleted_images INT NOT NULL DEFAULT 0,
+    clerk_unlinked BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX user_erasures_user_id_idx ON user_erasures (user_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS user_erasures;
@@ -0,0 +1,19 @@
+ALTER TABLE competitions ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE acts ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE ratings ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- A deleted rating must not keep its author from rating the act again.
+ALTER TABLE ratings DROP CONSTRAINT ratings_user_id_act_id_competition_id_key;
+CREATE UNIQUE INDEX ratings_user_id_act_id_competition_id_key ON ratings (user_id, act_id, competition_id) WHERE deleted_at IS NULL;
+
+---- create above / drop below ----
+
+-- Deleted ratings cannot be kept without the column, they would also
+-- violate the unique constraint.
+DELETE FROM ratings WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS ratings_user_id_act_id_competition_id_key;
+ALTER TABLE ratings ADD CONSTRAINT ratings_user_id_act_id_competition_id_key UNIQUE (user_id, act_id, competition_id);
+
+ALTER TABLE ratings DROP COLUMN deleted_at;
+ALTER TABLE acts DROP COLUMN deleted_at;
+ALTER TABLE competitions DROP COLUMN deleted_at;
@@ -0,0 +1,61 @@
+-- Scores of categories with the same name but different ranges cannot be
+-- averaged together, so the category stats are kept per name and range.
+DELETE FROM user_category_stats;
+DELETE FROM global_category_stats;
+
+ALTER TABLE user_category_stats DROP CONSTRAINT user_category_stats_pkey;
+ALTER TABLE user_category_stats ADD COLUMN min INT NOT NULL;
+ALTER TABLE user_category_stats ADD COLUMN max INT NOT NULL;
+ALTER TABLE user_category_stats ADD PRIMARY KEY (user_id, category, min, max);
+
+ALTER TABLE global_category_stats DROP CONSTRAINT global_category_stats_pkey;
+ALTER TABLE global_category_stats ADD COLUMN min INT NOT NULL;
+ALTER TABLE global_category_stats ADD COLUMN max INT NOT NULL;
+ALTER TABLE global_category_stats ADD PRIMARY KEY (category, min, max);
+
+INSERT INTO user_category_stats (user_id, category, min, max, score_sum, score_count)
+SELECT r.user_id, cc.name, cc.min, cc.max, SUM(rs.score), COUNT(*)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY r.user_id, cc.name, cc.min, cc.max;
+
+INSERT INTO global_category_stats (category, min, max, score_sum, score_count)
+SELECT cc.name, cc.min, cc.max, SUM(rs.score), COUNT(*)
+FROM rating_scores rs
+JOIN ratings r ON r.id = rs.rating_id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY cc.name, cc.min, cc.max;
+
+---- create above / drop below ----
+
+DELETE FROM user_category_stats;
+DELETE FROM global_category_stats;
+
+ALTER TABLE global_category_stats DROP CONSTRAINT global_category_stats_pkey;
+ALTER TABLE global_category_stats DROP COLUMN max;
+ALTER TABLE global_category_stats DROP COLUMN min;
+ALTER TABLE global_category_stats ADD PRIMARY KEY (category);
+
+ALTER TABLE user_category_stats DROP CONSTRAINT user_category_stats_pkey;
+ALTER TABLE user_category_stats DROP COLUMN max;
+ALTER TABLE user_category_stats DROP COLUMN min;
+ALTER TABLE user_category_stats ADD PRIMARY KEY (user_id, category);
+
+INSERT INTO user_category_stats (user_id, category, score_sum, score_count)
+SELECT r.user_id, cc.name, SUM(rs.score), COUNT(*)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY r.user_id, cc.name;
+
+INSERT INTO global_category_stats (category, score_sum, score_count)
+SELECT cc.name, SUM(rs.score), COUNT(*)
+FROM rating_scores rs
+JOIN ratings r ON r.id = rs.rating_id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY cc.name;
@@ -0,0 +1,5 @@
+CREATE INDEX rating_events_created_at_idx ON rating_events (created_at);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS rating_events_created_at_idx;
@@ -0,0 +1,42 @@
+-- The page queries compare and order by the sort field and the id. Their
+-- sort arguments are constants in custom plans, which the service forces,
+-- so the other sort fields fold away and these indexes serve the pages.
+CREATE INDEX acts_artist_name_id_idx ON acts (artist_name, id) WHERE deleted_at IS NULL;
+CREATE INDEX acts_song_name_id_idx ON acts (song_name, id) WHERE deleted_at IS NULL;
+CREATE INDEX acts_created_at_id_idx ON acts (created_at, id) WHERE deleted_at IS NULL;
+
+CREATE INDEX competitions_start_time_id_idx ON competitions (start_time, id) WHERE deleted_at IS NULL;
+CREATE INDEX competitions_city_id_idx ON competitions (city, id) WHERE deleted_at IS NULL;
+CREATE INDEX competitions_country_id_idx ON competitions (country, id) WHERE deleted_at IS NULL;
+CREATE INDEX competitions_created_at_id_idx ON competitions (created_at, id) WHERE deleted_at IS NULL;
+
+CREATE INDEX ratings_created_at_id_idx ON ratings (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX ratings_total_id_idx ON ratings (total, id) WHERE deleted_at IS NULL;
+
+CREATE INDEX users_firstname_id_idx ON users (firstname, id);
+CREATE INDEX users_lastname_id_idx ON users (lastname, id);
+CREATE INDEX users_created_at_id_idx ON users (created_at, id);
+
+CREATE INDEX user_stats_rating_avg_user_id_idx ON user_stats ((COALESCE(rating_avg, 0)), user_id);
+CREATE INDEX user_stats_rating_count_user_id_idx ON user_stats ((COALESCE(rating_count, 0)), user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS user_stats_rating_count_user_id_idx;
+DROP INDEX IF EXISTS user_stats_rating_avg_user_id_idx;
+
+DROP INDEX IF EXISTS users_created_at_id_idx;
+DROP INDEX IF EXISTS users_lastname_id_idx;
+DROP INDEX IF EXISTS users_firstname_id_idx;
+
+DROP INDEX IF EXISTS ratings_total_id_idx;
+DROP INDEX IF EXISTS ratings_created_at_id_idx;
+
+DROP INDEX IF EXISTS competitions_created_at_id_idx;
+DROP INDEX IF EXISTS competitions_country_id_idx;
+DROP INDEX IF EXISTS competitions_city_id_idx;
+DROP INDEX IF EXISTS competitions_start_time_id_idx;
+
+DROP INDEX IF EXISTS acts_created_at_id_idx;
+DROP INDEX IF EXISTS acts_song_name_id_idx;
+DROP INDEX IF EXISTS acts_artist_name_id_idx;
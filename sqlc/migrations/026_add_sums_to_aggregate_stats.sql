@@ -0,0 +1,39 @@
+-- The sums let rating writes update the act and competition stats
+-- incrementally instead of aggregating all ratings of the competition.
+ALTER TABLE act_stats ADD COLUMN rating_sum NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE act_stats ADD COLUMN rating_sum_squares NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE competition_stats ADD COLUMN rating_sum NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE competition_stats ADD COLUMN rating_sum_squares NUMERIC NOT NULL DEFAULT 0;
+
+UPDATE act_stats s
+SET rating_sum = a.rating_sum, rating_sum_squares = a.rating_sum_squares
+FROM (
+    SELECT r.competition_id, r.act_id, cc.name AS category, SUM(rs.score) AS rating_sum, SUM(rs.score * rs.score) AS rating_sum_squares
+    FROM ratings r
+    JOIN rating_scores rs ON rs.rating_id = r.id
+    JOIN competition_categories cc ON cc.id = rs.category_id
+    WHERE r.deleted_at IS NULL
+    GROUP BY r.competition_id, r.act_id, cc.name
+    UNION ALL
+    SELECT r.competition_id, r.act_id, '', SUM(r.total), SUM(r.total::NUMERIC * r.total)
+    FROM ratings r
+    WHERE r.deleted_at IS NULL
+    GROUP BY r.competition_id, r.act_id
+) a
+WHERE s.competition_id = a.competition_id AND s.act_id = a.act_id AND s.category = a.category;
+
+UPDATE competition_stats s
+SET rating_sum = a.rating_sum, rating_sum_squares = a.rating_sum_squares
+FROM (
+    SELECT competition_id, category, SUM(rating_sum) AS rating_sum, SUM(rating_sum_squares) AS rating_sum_squares
+    FROM act_stats
+    GROUP BY competition_id, category
+) a
+WHERE s.competition_id = a.competition_id AND s.category = a.category;
+
+---- create above / drop below ----
+
+ALTER TABLE competition_stats DROP COLUMN rating_sum_squares;
+ALTER TABLE competition_stats DROP COLUMN rating_sum;
+ALTER TABLE act_stats DROP COLUMN rating_sum_squares;
+ALTER TABLE act_stats DROP COLUMN rating_sum;
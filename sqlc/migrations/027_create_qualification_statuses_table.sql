@@ -0,0 +1,14 @@
+-- Whether the qualifiers of a competition are complete, either because the
+-- expected number of qualifiers has been marked or because an admin marked
+-- them as final. Qualifier predictions are only scored once they are.
+CREATE TABLE qualification_statuses (
+    competition_id UUID PRIMARY KEY REFERENCES competitions(id) ON DELETE CASCADE,
+    expected_count INT CHECK (expected_count > 0),
+    is_final BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS qualification_statuses;
@@ -0,0 +1,114 @@
+-- name: ListActStatsByActId :many
+SELECT
+    s.*,
+    ranks.rank
+FROM act_stats s
+JOIN (
+    SELECT
+        competition_id,
+        act_id,
+        RANK() OVER (PARTITION BY competition_id ORDER BY rating_avg DESC)::INT AS rank
+    FROM act_stats
+    WHERE category = ''
+) ranks ON ranks.competition_id = s.competition_id AND ranks.act_id = s.act_id
+WHERE s.act_id = $1
+ORDER BY s.competition_id, s.category;
+
+-- name: ListActTotalStatsByCompetitionId :many
+SELECT
+    *,
+    RANK() OVER (ORDER BY rating_avg DESC)::INT AS rank
+FROM act_stats
+WHERE competition_id = $1 AND category = ''
+ORDER BY rank, act_id;
+
+-- name: UpsertActStatsFromRatings :exec
+INSERT INTO act_stats (competition_id, act_id, category, rating_count, rating_sum, rating_sum_squares, rating_avg, rating_variance, rating_min, rating_max)
+SELECT r.competition_id, r.act_id, cc.name, COUNT(*), SUM(rs.score), SUM(rs.score * rs.score), AVG(rs.score), VAR_POP(rs.score), MIN(rs.score), MAX(rs.score)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+    AND (sqlc.narg(competition_id)::UUID IS NULL OR r.competition_id = sqlc.narg(competition_id))
+    AND (sqlc.narg(act_id)::UUID IS NULL OR r.act_id = sqlc.narg(act_id))
+GROUP BY r.competition_id, r.act_id, cc.name
+UNION ALL
+SELECT r.competition_id, r.act_id, '', COUNT(*), SUM(r.total), SUM(r.total::NUMERIC * r.total), AVG(r.total), VAR_POP(r.total), MIN(r.total), MAX(r.total)
+FROM ratings r
+WHERE r.deleted_at IS NULL
+    AND (sqlc.narg(competition_id)::UUID IS NULL OR r.competition_id = sqlc.narg(competition_id))
+    AND (sqlc.narg(act_id)::UUID IS NULL OR r.act_id = sqlc.narg(act_id))
+GROUP BY r.competition_id, r.act_id
+ON CONFLICT (competition_id, act_id, category) DO UPDATE
+SET
+    rating_count = EXCLUDED.rating_count,
+    rating_sum = EXCLUDED.rating_sum,
+    rating_sum_squares = EXCLUDED.rating_sum_squares,
+    rating_avg = EXCLUDED.rating_avg,
+    rating_variance = EXCLUDED.rating_variance,
+    rating_min = EXCLUDED.rating_min,
+    rating_max = EXCLUDED.rating_max,
+    updated_at = NOW();
+
+-- name: DeleteActStatsWithoutRatings :exec
+DELETE FROM act_stats s
+WHERE (sqlc.narg(competition_id)::UUID IS NULL OR s.competition_id = sqlc.narg(competition_id))
+    AND (sqlc.narg(act_id)::UUID IS NULL OR s.act_id = sqlc.narg(act_id))
+    AND NOT EXISTS (
+        SELECT 1 FROM ratings r
+        WHERE r.competition_id = s.competition_id AND r.act_id = s.act_id AND r.deleted_at IS NULL
+    );
+
+-- name: AddToActStats :exec
+INSERT INTO act_stats AS s (competition_id, act_id, category, rating_count, rating_sum, rating_sum_squares, rating_avg, rating_variance, rating_min, rating_max)
+VALUES (
+    sqlc.arg(competition_id), sqlc.arg(act_id), sqlc.arg(category),
+    1, sqlc.arg(score)::INT, sqlc.arg(score)::INT * sqlc.arg(score)::INT, sqlc.arg(score)::INT, 0, sqlc.arg(score)::INT, sqlc.arg(score)::INT
+)
+ON CONFLICT (competition_id, act_id, category) DO UPDATE
+SET
+    rating_count = s.rating_count + 1,
+    rating_sum = s.rating_sum + EXCLUDED.rating_sum,
+    rating_sum_squares = s.rating_sum_squares + EXCLUDED.rating_sum_squares,
+    rating_avg = (s.rating_sum + EXCLUDED.rating_sum) / (s.rating_count + 1),
+    rating_variance = GREATEST((s.rating_sum_squares + EXCLUDED.rating_sum_squares) / (s.rating_count + 1) - POWER((s.rating_sum + EXCLUDED.rating_sum) / (s.rating_count + 1), 2), 0),
+    rating_min = LEAST(s.rating_min, EXCLUDED.rating_min),
+    rating_max = GREATEST(s.rating_max, EXCLUDED.rating_max),
+    updated_at = NOW();
+
+-- name: RemoveFromActStats :one
+-- The min and max are kept, they have to be refreshed if the removed score
+-- was one of them.
+UPDATE act_stats s
+SET
+    rating_count = s.rating_count - 1,
+    rating_sum = s.rating_sum - sqlc.arg(score)::INT,
+    rating_sum_squares = s.rating_sum_squares - sqlc.arg(score)::INT * sqlc.arg(score)::INT,
+    rating_avg = CASE WHEN s.rating_count > 1 THEN (s.rating_sum - sqlc.arg(score)::INT) / (s.rating_count - 1) ELSE 0 END,
+    rating_variance = CASE
+        WHEN s.rating_count > 1 THEN GREATEST((s.rating_sum_squares - sqlc.arg(score)::INT * sqlc.arg(score)::INT) / (s.rating_count - 1) - POWER((s.rating_sum - sqlc.arg(score)::INT) / (s.rating_count - 1), 2), 0)
+        ELSE 0
+    END,
+    updated_at = NOW()
+WHERE s.competition_id = sqlc.arg(competition_id) AND s.act_id = sqlc.arg(act_id) AND s.category = sqlc.arg(category) AND s.rating_count > 0
+RETURNING *;
+
+-- name: RefreshActStatsRange :exec
+UPDATE act_stats s
+SET rating_min = v.rating_min, rating_max = v.rating_max
+FROM (
+    SELECT MIN(rs.score) AS rating_min, MAX(rs.score) AS rating_max
+    FROM ratings r
+    JOIN rating_scores rs ON rs.rating_id = r.id
+    JOIN competition_categories cc ON cc.id = rs.category_id
+    WHERE r.competition_id = sqlc.arg(competition_id) AND r.act_id = sqlc.arg(act_id) AND cc.name = sqlc.arg(category) AND r.deleted_at IS NULL
+    UNION ALL
+    SELECT MIN(r.total), MAX(r.total)
+    FROM ratings r
+    WHERE r.competition_id = sqlc.arg(competition_id) AND r.act_id = sqlc.arg(act_id) AND sqlc.arg(category) = '' AND r.deleted_at IS NULL
+) v
+WHERE s.competition_id = sqlc.arg(competition_id) AND s.act_id = sqlc.arg(act_id) AND s.category = sqlc.arg(category)
+    AND v.rating_min IS NOT NULL;
+
+-- name: DeleteEmptyActStats :exec
+DELETE FROM act_stats WHERE competition_id = $1 AND act_id = $2 AND rating_count = 0;
@@ -1,13 +1,13 @@
 -- name: ListActs :many
-SELECT * FROM acts;
+SELECT * FROM acts WHERE deleted_at IS NULL;
 
 -- name: ListActsByCompetitionId :many
 SELECT a.*, ca.order FROM acts a
 JOIN competitions_acts ca ON a.id = ca.act_id
-WHERE ca.competition_id = $1;
+WHERE ca.competition_id = $1 AND a.deleted_at IS NULL;
 
 -- name: GetActById :one
-SELECT * FROM acts WHERE id = $1 LIMIT 1;
+SELECT * FROM acts WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: InsertAct :one
 INSERT INTO
@@ -23,7 +23,51 @@ SET
     image_url = $3,
     updated_at = NOW()
 WHERE
-    id = $4 RETURNING *;
+    id = $4 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeleteActById :one
-DELETE FROM acts WHERE id = $1 RETURNING *;
\ No newline at end of file
+DELETE FROM acts WHERE id = $1 RETURNING *;
+
+-- name: SoftDeleteActById :one
+UPDATE acts SET deleted_at = sqlc.arg(deleted_at) WHERE id = sqlc.arg(id) AND deleted_at IS NULL RETURNING *;
+
+-- name: GetDeletedActByIdForUpdate :one
+SELECT * FROM acts WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1 FOR UPDATE;
+
+-- name: RestoreActById :one
+UPDATE acts SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING *;
+
+-- name: ListActsPage :many
+SELECT * FROM acts
+WHERE
+    deleted_at IS NULL
+    AND (
+        sqlc.narg(search)::TEXT IS NULL
+        OR artist_name ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+        OR song_name ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+    )
+    AND (
+        sqlc.narg(after_id)::UUID IS NULL
+        OR (sqlc.arg(sort_by)::TEXT = 'artistName' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (artist_name, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'artistName' AND sqlc.arg(sort_desc)::BOOLEAN AND (artist_name, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'songName' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (song_name, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'songName' AND sqlc.arg(sort_desc)::BOOLEAN AND (song_name, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'artistName' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN artist_name END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'artistName' AND sqlc.arg(sort_desc)::BOOLEAN THEN artist_name END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'songName' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN song_name END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'songName' AND sqlc.arg(sort_desc)::BOOLEAN THEN song_name END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::BOOLEAN THEN id END DESC,
+    id ASC
+LIMIT sqlc.arg(row_limit)::INT;
+
+-- name: GetActByArtistAndSong :one
+SELECT * FROM acts
+WHERE LOWER(artist_name) = LOWER(sqlc.arg(artist_name)) AND LOWER(song_name) = LOWER(sqlc.arg(song_name)) AND deleted_at IS NULL
+ORDER BY created_at ASC
+LIMIT 1;
@@ -0,0 +1,64 @@
+-- name: ListUserCategoryStatsByUserId :many
+SELECT * FROM user_category_stats WHERE user_id = $1 ORDER BY category, min, max;
+
+-- name: ComputeUserCategoryStatsFromRatings :many
+SELECT
+    cc.name AS category,
+    cc.min,
+    cc.max,
+    SUM(rs.score)::BIGINT AS score_sum,
+    COUNT(*)::INT AS score_count
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.user_id = $1 AND r.deleted_at IS NULL
+GROUP BY cc.name, cc.min, cc.max
+ORDER BY cc.name, cc.min, cc.max;
+
+-- name: UpsertUserCategoryStats :exec
+INSERT INTO user_category_stats (user_id, category, min, max, score_sum, score_count)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, category, min, max) DO UPDATE
+SET
+    score_sum = EXCLUDED.score_sum,
+    score_count = EXCLUDED.score_count,
+    updated_at = NOW();
+
+-- name: DeleteUserCategoryStats :exec
+DELETE FROM user_category_stats WHERE user_id = $1 AND category = $2 AND min = $3 AND max = $4;
+
+-- name: ListGlobalCategoryStats :many
+SELECT * FROM global_category_stats ORDER BY category, min, max;
+
+-- name: AddToGlobalCategoryStats :exec
+INSERT INTO global_category_stats (category, min, max, score_sum, score_count)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (category, min, max) DO UPDATE
+SET
+    score_sum = global_category_stats.score_sum + EXCLUDED.score_sum,
+    score_count = global_category_stats.score_count + EXCLUDED.score_count,
+    updated_at = NOW();
+
+-- name: DeleteAllUserCategoryStats :exec
+DELETE FROM user_category_stats;
+
+-- name: DeleteAllGlobalCategoryStats :exec
+DELETE FROM global_category_stats;
+
+-- name: InsertAllUserCategoryStatsFromRatings :exec
+INSERT INTO user_category_stats (user_id, category, min, max, score_sum, score_count)
+SELECT r.user_id, cc.name, cc.min, cc.max, SUM(rs.score), COUNT(*)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY r.user_id, cc.name, cc.min, cc.max;
+
+-- name: InsertAllGlobalCategoryStatsFromRatings :exec
+INSERT INTO global_category_stats (category, min, max, score_sum, score_count)
+SELECT cc.name, cc.min, cc.max, SUM(rs.score), COUNT(*)
+FROM rating_scores rs
+JOIN ratings r ON r.id = rs.rating_id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL
+GROUP BY cc.name, cc.min, cc.max;
@@ -8,3 +8,19 @@ VALUES ($1, $2, $3);
 
 -- name: DeleteCompetitionAct :exec
 DELETE FROM competitions_acts WHERE competition_id = $1 AND act_id = $2;
+
+-- name: GetCompetitionAct :one
+SELECT * FROM competitions_acts WHERE competition_id = $1 AND act_id = $2 LIMIT 1;
+
+-- name: UpdateCompetitionActOrder :exec
+UPDATE competitions_acts SET "order" = $3 WHERE competition_id = $1 AND act_id = $2;
+
+-- name: HasCompetitionActsByCompetitionId :one
+SELECT EXISTS (
+    SELECT 1 FROM competitions_acts WHERE competition_id = $1
+);
+
+-- name: HasCompetitionActsByActId :one
+SELECT EXISTS (
+    SELECT 1 FROM competitions_acts WHERE act_id = $1
+);
@@ -0,0 +1,10 @@
+-- name: ListCompetitionCategoriesByCompetitionId :many
+SELECT * FROM competition_categories WHERE competition_id = $1 ORDER BY position ASC;
+
+-- name: InsertCompetitionCategory :one
+INSERT INTO
+    competition_categories (competition_id, name, min, max, weight, position)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: DeleteCompetitionCategoriesByCompetitionId :exec
+DELETE FROM competition_categories WHERE competition_id = $1;
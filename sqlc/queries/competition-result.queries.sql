@@ -0,0 +1,10 @@
+-- name: ListCompetitionResultsByCompetitionId :many
+SELECT * FROM competition_results WHERE competition_id = $1 ORDER BY placement ASC;
+
+-- name: InsertCompetitionResult :one
+INSERT INTO
+    competition_results (competition_id, act_id, placement, points, jury_points, televote_points)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: DeleteCompetitionResultsByCompetitionId :exec
+DELETE FROM competition_results WHERE competition_id = $1;
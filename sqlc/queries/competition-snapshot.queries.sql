@@ -0,0 +1,11 @@
+-- name: GetCompetitionSnapshotByCompetitionId :one
+SELECT * FROM competition_snapshots WHERE competition_id = $1 LIMIT 1;
+
+-- name: DeleteCompetitionSnapshotByCompetitionId :exec
+DELETE FROM competition_snapshots WHERE competition_id = $1;
+
+-- name: InsertCompetitionSnapshot :exec
+INSERT INTO
+    competition_snapshots (competition_id, scoreboard)
+VALUES ($1, $2)
+ON CONFLICT (competition_id) DO NOTHING;
@@ -0,0 +1,75 @@
+-- name: ListCompetitionStatsByCompetitionId :many
+SELECT * FROM competition_stats WHERE competition_id = $1 ORDER BY category;
+
+-- name: UpsertCompetitionStatsFromRatings :exec
+INSERT INTO competition_stats (competition_id, category, rating_count, rating_sum, rating_sum_squares, rating_avg, rating_variance, rating_min, rating_max)
+SELECT r.competition_id, cc.name, COUNT(*), SUM(rs.score), SUM(rs.score * rs.score), AVG(rs.score), VAR_POP(rs.score), MIN(rs.score), MAX(rs.score)
+FROM ratings r
+JOIN rating_scores rs ON rs.rating_id = r.id
+JOIN competition_categories cc ON cc.id = rs.category_id
+WHERE r.deleted_at IS NULL AND (sqlc.narg(competition_id)::UUID IS NULL OR r.competition_id = sqlc.narg(competition_id))
+GROUP BY r.competition_id, cc.name
+UNION ALL
+SELECT r.competition_id, '', COUNT(*), SUM(r.total), SUM(r.total::NUMERIC * r.total), AVG(r.total), VAR_POP(r.total), MIN(r.total), MAX(r.total)
+FROM ratings r
+WHERE r.deleted_at IS NULL AND (sqlc.narg(competition_id)::UUID IS NULL OR r.competition_id = sqlc.narg(competition_id))
+GROUP BY r.competition_id
+ON CONFLICT (competition_id, category) DO UPDATE
+SET
+    rating_count = EXCLUDED.rating_count,
+    rating_sum = EXCLUDED.rating_sum,
+    rating_sum_squares = EXCLUDED.rating_sum_squares,
+    rating_avg = EXCLUDED.rating_avg,
+    rating_variance = EXCLUDED.rating_variance,
+    rating_min = EXCLUDED.rating_min,
+    rating_max = EXCLUDED.rating_max,
+    updated_at = NOW();
+
+-- name: DeleteCompetitionStatsWithoutRatings :exec
+DELETE FROM competition_stats s
+WHERE (sqlc.narg(competition_id)::UUID IS NULL OR s.competition_id = sqlc.narg(competition_id))
+    AND NOT EXISTS (SELECT 1 FROM ratings r WHERE r.competition_id = s.competition_id AND r.deleted_at IS NULL);
+
+-- name: AddToCompetitionStats :exec
+INSERT INTO competition_stats AS s (competition_id, category, rating_count, rating_sum, rating_sum_squares, rating_avg, rating_variance, rating_min, rating_max)
+VALUES (
+    sqlc.arg(competition_id), sqlc.arg(category),
+    1, sqlc.arg(score)::INT, sqlc.arg(score)::INT * sqlc.arg(score)::INT, sqlc.arg(score)::INT, 0, sqlc.arg(score)::INT, sqlc.arg(score)::INT
+)
+ON CONFLICT (competition_id, category) DO UPDATE
+SET
+    rating_count = s.rating_count + 1,
+    rating_sum = s.rating_sum + EXCLUDED.rating_sum,
+    rating_sum_squares = s.rating_sum_squares + EXCLUDED.rating_sum_squares,
+    rating_avg = (s.rating_sum + EXCLUDED.rating_sum) / (s.rating_count + 1),
+    rating_variance = GREATEST((s.rating_sum_squares + EXCLUDED.rating_sum_squares) / (s.rating_count + 1) - POWER((s.rating_sum + EXCLUDED.rating_sum) / (s.rating_count + 1), 2), 0),
+    rating_min = LEAST(s.rating_min, EXCLUDED.rating_min),
+    rating_max = GREATEST(s.rating_max, EXCLUDED.rating_max),
+    updated_at = NOW();
+
+-- name: RemoveFromCompetitionStats :exec
+-- The min and max are taken from the act stats, which have to be updated
+-- first.
+UPDATE competition_stats s
+SET
+    rating_count = s.rating_count - 1,
+    rating_sum = s.rating_sum - sqlc.arg(score)::INT,
+    rating_sum_squares = s.rating_sum_squares - sqlc.arg(score)::INT * sqlc.arg(score)::INT,
+    rating_avg = CASE WHEN s.rating_count > 1 THEN (s.rating_sum - sqlc.arg(score)::INT) / (s.rating_count - 1) ELSE 0 END,
+    rating_variance = CASE
+        WHEN s.rating_count > 1 THEN GREATEST((s.rating_sum_squares - sqlc.arg(score)::INT * sqlc.arg(score)::INT) / (s.rating_count - 1) - POWER((s.rating_sum - sqlc.arg(score)::INT) / (s.rating_count - 1), 2), 0)
+        ELSE 0
+    END,
+    rating_min = COALESCE((
+        SELECT MIN(a.rating_min) FROM act_stats a
+        WHERE a.competition_id = s.competition_id AND a.category = s.category AND a.rating_count > 0
+    ), 0),
+    rating_max = COALESCE((
+        SELECT MAX(a.rating_max) FROM act_stats a
+        WHERE a.competition_id = s.competition_id AND a.category = s.category AND a.rating_count > 0
+    ), 0),
+    updated_at = NOW()
+WHERE s.competition_id = sqlc.arg(competition_id) AND s.category = sqlc.arg(category) AND s.rating_count > 0;
+
+-- name: DeleteEmptyCompetitionStats :exec
+DELETE FROM competition_stats WHERE competition_id = $1 AND rating_count = 0;
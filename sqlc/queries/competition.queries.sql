@@ -1,8 +1,8 @@
 -- name: ListCompetitions :many
-SELECT * FROM competitions ORDER BY start_time ASC;
+SELECT * FROM competitions WHERE deleted_at IS NULL ORDER BY start_time ASC;
 
 -- name: GetCompetitionById :one
-SELECT * FROM competitions WHERE id = $1 LIMIT 1;
+SELECT * FROM competitions WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: InsertCompetition :one
 INSERT INTO
@@ -20,7 +20,106 @@ SET
     image_url = $5,
     updated_at = NOW()
 WHERE
-    id = $6 RETURNING *;
+    id = $6 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeleteCompetitionById :one
-DELETE FROM competitions WHERE id = $1 RETURNING *;
\ No newline at end of file
+DELETE FROM competitions WHERE id = $1 RETURNING *;
+
+-- name: SoftDeleteCompetitionById :one
+UPDATE competitions SET deleted_at = sqlc.arg(deleted_at) WHERE id = sqlc.arg(id) AND deleted_at IS NULL RETURNING *;
+
+-- name: GetDeletedCompetitionByIdForUpdate :one
+SELECT * FROM competitions WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1 FOR UPDATE;
+
+-- name: RestoreCompetitionById :one
+UPDATE competitions SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING *;
+
+-- name: GetCompetitionByIdForUpdate :one
+SELECT * FROM competitions WHERE id = $1 AND deleted_at IS NULL LIMIT 1 FOR UPDATE;
+
+-- name: UpdateCompetitionLiveState :one
+UPDATE
+    competitions
+SET
+    live_state = $1,
+    live_act_order = $2,
+    updated_at = NOW()
+WHERE
+    id = $3 AND deleted_at IS NULL RETURNING *;
+
+-- name: UpdateCompetitionPerformedActsOnly :one
+UPDATE
+    competitions
+SET
+    performed_acts_only = $1,
+    updated_at = NOW()
+WHERE
+    id = $2 AND deleted_at IS NULL RETURNING *;
+
+-- name: UpdateCompetitionVotingClosesAt :one
+UPDATE
+    competitions
+SET
+    voting_closes_at = $1,
+    updated_at = NOW()
+WHERE
+    id = $2 AND deleted_at IS NULL RETURNING *;
+
+-- name: UpdateCompetitionLockedAt :one
+UPDATE
+    competitions
+SET
+    locked_at = $1,
+    updated_at = NOW()
+WHERE
+    id = $2 AND deleted_at IS NULL RETURNING *;
+
+-- name: GetCompetitionByIdForNoKeyUpdate :one
+SELECT * FROM competitions WHERE id = $1 AND deleted_at IS NULL LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: GetCompetitionByIdForShare :one
+SELECT * FROM competitions WHERE id = $1 AND deleted_at IS NULL LIMIT 1 FOR SHARE;
+
+-- name: ListCompetitionsPage :many
+SELECT * FROM competitions
+WHERE
+    deleted_at IS NULL
+    AND (sqlc.narg(country)::TEXT IS NULL OR LOWER(country) = LOWER(sqlc.narg(country)::TEXT))
+    AND (sqlc.narg(heat)::HEAT IS NULL OR heat = sqlc.narg(heat)::HEAT)
+    AND (sqlc.narg(year)::INT IS NULL OR EXTRACT(YEAR FROM start_time)::INT = sqlc.narg(year)::INT)
+    AND (sqlc.narg(season_id)::UUID IS NULL OR season_id = sqlc.narg(season_id)::UUID)
+    AND (
+        sqlc.narg(after_id)::UUID IS NULL
+        OR (sqlc.arg(sort_by)::TEXT = 'startTime' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (start_time, id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'startTime' AND sqlc.arg(sort_desc)::BOOLEAN AND (start_time, id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'city' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (city, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'city' AND sqlc.arg(sort_desc)::BOOLEAN AND (city, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'country' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (country, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'country' AND sqlc.arg(sort_desc)::BOOLEAN AND (country, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'startTime' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN start_time END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'startTime' AND sqlc.arg(sort_desc)::BOOLEAN THEN start_time END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'city' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN city END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'city' AND sqlc.arg(sort_desc)::BOOLEAN THEN city END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'country' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN country END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'country' AND sqlc.arg(sort_desc)::BOOLEAN THEN country END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::BOOLEAN THEN id END DESC,
+    id ASC
+LIMIT sqlc.arg(row_limit)::INT;
+
+-- name: ListCompetitionsBySeasonId :many
+SELECT * FROM competitions WHERE season_id = $1 AND deleted_at IS NULL ORDER BY start_time ASC;
+
+-- name: UpdateCompetitionSeasonId :one
+UPDATE
+    competitions
+SET
+    season_id = sqlc.narg(season_id),
+    updated_at = NOW()
+WHERE
+    id = sqlc.arg(id) AND deleted_at IS NULL RETURNING *;
@@ -15,3 +15,41 @@ SET
     rating_count = $2,
     updated_at = NOW()
 RETURNING *;
+
+-- name: AddToGlobalStats :exec
+INSERT INTO global_stats (id, rating_avg, rating_count)
+VALUES (TRUE, sqlc.arg(total)::INT, 1)
+ON CONFLICT (id) DO UPDATE
+SET
+    rating_avg = ROUND(
+        (COALESCE(global_stats.rating_avg, 0) * COALESCE(global_stats.rating_count, 0) + EXCLUDED.rating_avg)
+        / (COALESCE(global_stats.rating_count, 0) + 1),
+        2
+    ),
+    rating_count = COALESCE(global_stats.rating_count, 0) + 1,
+    updated_at = NOW();
+
+-- name: UpdateInGlobalStats :exec
+UPDATE global_stats
+SET
+    rating_avg = ROUND((rating_avg * rating_count - sqlc.arg(old_total)::INT + sqlc.arg(new_total)::INT) / rating_count, 2),
+    updated_at = NOW()
+WHERE id = TRUE AND rating_count > 0;
+
+-- name: RemoveFromGlobalStats :exec
+UPDATE global_stats
+SET
+    rating_avg = CASE
+        WHEN rating_count > 1 THEN ROUND((rating_avg * rating_count - sqlc.arg(total)::INT) / (rating_count - 1), 2)
+        ELSE 0
+    END,
+    rating_count = rating_count - 1,
+    updated_at = NOW()
+WHERE id = TRUE AND rating_count > 0;
+
+-- name: ComputeGlobalStatsFromRatings :one
+SELECT
+    ROUND(AVG(total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings
+WHERE deleted_at IS NULL;
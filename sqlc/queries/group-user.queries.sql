@@ -0,0 +1,16 @@
+-- name: InsertGroupUser :exec
+INSERT INTO
+    groups_users (group_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: DeleteGroupUser :exec
+DELETE FROM groups_users WHERE group_id = $1 AND user_id = $2;
+
+-- name: IsGroupUser :one
+SELECT EXISTS (
+    SELECT 1 FROM groups_users WHERE group_id = $1 AND user_id = $2
+);
+
+-- name: DeleteGroupUsersByUserId :exec
+DELETE FROM groups_users WHERE user_id = $1;
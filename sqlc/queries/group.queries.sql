@@ -0,0 +1,78 @@
+-- name: ListGroupsByUserId :many
+SELECT g.* FROM groups g
+JOIN groups_users gu ON g.id = gu.group_id
+WHERE gu.user_id = $1
+ORDER BY g.created_at ASC;
+
+-- name: GetGroupById :one
+SELECT * FROM groups WHERE id = $1 LIMIT 1;
+
+-- name: GetGroupByInviteCode :one
+SELECT * FROM groups WHERE invite_code = $1 LIMIT 1;
+
+-- name: InsertGroup :one
+INSERT INTO
+    groups (name, invite_code, owner_id)
+VALUES ($1, $2, $3) RETURNING *;
+
+-- name: UpdateGroup :one
+UPDATE
+    groups
+SET
+    name = $1,
+    updated_at = NOW()
+WHERE
+    id = $2 RETURNING *;
+
+-- name: UpdateGroupInviteCode :one
+UPDATE
+    groups
+SET
+    invite_code = $1,
+    updated_at = NOW()
+WHERE
+    id = $2 RETURNING *;
+
+-- name: DeleteGroupById :one
+DELETE FROM groups WHERE id = $1 RETURNING *;
+
+-- name: TransferGroupsOwnedByUserId :execrows
+-- Hands every group the user owns to its longest standing other member.
+UPDATE groups g
+SET
+    owner_id = (
+        SELECT gu.user_id FROM groups_users gu
+        WHERE gu.group_id = g.id AND gu.user_id <> $1
+        ORDER BY gu.created_at ASC
+        LIMIT 1
+    ),
+    updated_at = NOW()
+WHERE g.owner_id = $1 AND EXISTS (
+    SELECT 1 FROM groups_users gu WHERE gu.group_id = g.id AND gu.user_id <> $1
+);
+
+-- name: DeleteGroupsByOwnerId :execrows
+DELETE FROM groups WHERE owner_id = $1;
+
+-- name: ComputeUserStatsByGroupId :many
+-- Only the ratings of the members count, the competitions of a group are
+-- the competitions its members rated.
+SELECT
+    r.user_id,
+    ROUND(AVG(r.total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings r
+JOIN groups_users gu ON r.user_id = gu.user_id
+JOIN competitions c ON r.competition_id = c.id
+WHERE gu.group_id = $1 AND r.deleted_at IS NULL AND c.deleted_at IS NULL
+GROUP BY r.user_id
+ORDER BY rating_avg DESC, r.user_id;
+
+-- name: ComputeGroupStatsByGroupId :one
+SELECT
+    ROUND(AVG(r.total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings r
+JOIN groups_users gu ON r.user_id = gu.user_id
+JOIN competitions c ON r.competition_id = c.id
+WHERE gu.group_id = $1 AND r.deleted_at IS NULL AND c.deleted_at IS NULL;
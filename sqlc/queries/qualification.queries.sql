@@ -0,0 +1,83 @@
+-- name: ListQualifications :many
+SELECT * FROM qualifications
+WHERE sqlc.narg(competition_id)::UUID IS NULL OR competition_id = sqlc.narg(competition_id)::UUID
+ORDER BY competition_id, created_at;
+
+-- name: InsertQualification :one
+INSERT INTO
+    qualifications (competition_id, act_id, target_competition_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (competition_id, act_id) DO UPDATE SET
+    target_competition_id = EXCLUDED.target_competition_id
+RETURNING *;
+
+-- name: DeleteQualification :one
+DELETE FROM qualifications WHERE competition_id = $1 AND act_id = $2 RETURNING *;
+
+-- name: GetQualificationStatusByCompetitionId :one
+SELECT
+    c.id AS competition_id,
+    s.expected_count,
+    COALESCE(s.is_final, FALSE)::BOOLEAN AS is_final,
+    (SELECT COUNT(*) FROM qualifications q WHERE q.competition_id = c.id)::INT AS qualifier_count
+FROM competitions c
+LEFT JOIN qualification_statuses s ON s.competition_id = c.id
+WHERE c.id = $1 AND c.deleted_at IS NULL;
+
+-- name: UpsertQualificationStatus :exec
+INSERT INTO
+    qualification_statuses (competition_id, expected_count, is_final)
+VALUES ($1, $2, $3)
+ON CONFLICT (competition_id) DO UPDATE SET
+    expected_count = EXCLUDED.expected_count,
+    is_final = EXCLUDED.is_final,
+    updated_at = NOW();
+
+-- name: ListQualificationPredictionsByUserId :many
+SELECT * FROM qualification_predictions
+WHERE user_id = $1 AND competition_id = $2
+ORDER BY created_at;
+
+-- name: ListAllQualificationPredictionsByUserId :many
+SELECT * FROM qualification_predictions
+WHERE user_id = $1
+ORDER BY competition_id, created_at;
+
+-- name: InsertQualificationPrediction :exec
+INSERT INTO
+    qualification_predictions (user_id, competition_id, act_id)
+VALUES ($1, $2, $3);
+
+-- name: DeleteQualificationPredictionsByUserId :exec
+DELETE FROM qualification_predictions WHERE user_id = $1 AND competition_id = $2;
+
+-- name: ListQualificationPredictionScores :many
+-- Only competitions whose qualifiers are complete are scored.
+WITH decided AS (
+    SELECT q.competition_id, COUNT(*)::INT AS qualifier_count
+    FROM qualifications q
+    JOIN competitions c ON q.competition_id = c.id AND c.deleted_at IS NULL
+    JOIN qualification_statuses s ON q.competition_id = s.competition_id
+    WHERE sqlc.narg(competition_id)::UUID IS NULL OR q.competition_id = sqlc.narg(competition_id)::UUID
+    GROUP BY q.competition_id, s.expected_count, s.is_final
+    HAVING s.is_final OR COUNT(*) >= s.expected_count
+), user_competitions AS (
+    SELECT
+        p.user_id,
+        p.competition_id,
+        COUNT(*)::INT AS prediction_count,
+        COUNT(q.act_id)::INT AS correct_count
+    FROM qualification_predictions p
+    JOIN decided d ON p.competition_id = d.competition_id
+    LEFT JOIN qualifications q ON p.competition_id = q.competition_id AND p.act_id = q.act_id
+    GROUP BY p.user_id, p.competition_id
+)
+SELECT
+    uc.user_id,
+    COUNT(*)::INT AS competition_count,
+    SUM(uc.prediction_count)::INT AS prediction_count,
+    SUM(uc.correct_count)::INT AS correct_count,
+    SUM(d.qualifier_count)::INT AS qualifier_count
+FROM user_competitions uc
+JOIN decided d ON uc.competition_id = d.competition_id
+GROUP BY uc.user_id;
@@ -0,0 +1,29 @@
+-- name: ListRankingPredictionsByUserId :many
+SELECT * FROM ranking_predictions
+WHERE user_id = $1 AND competition_id = $2
+ORDER BY position ASC;
+
+-- name: ListAllRankingPredictionsByUserId :many
+SELECT * FROM ranking_predictions
+WHERE user_id = $1
+ORDER BY competition_id, position ASC;
+
+-- name: InsertRankingPrediction :exec
+INSERT INTO
+    ranking_predictions (user_id, competition_id, act_id, position)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteRankingPredictionsByUserId :exec
+DELETE FROM ranking_predictions WHERE user_id = $1 AND competition_id = $2;
+
+-- name: ListScoredRankingPredictions :many
+SELECT
+    p.user_id,
+    p.competition_id,
+    p.position,
+    r.placement
+FROM ranking_predictions p
+JOIN competition_results r ON p.competition_id = r.competition_id AND p.act_id = r.act_id
+JOIN competitions c ON p.competition_id = c.id AND c.deleted_at IS NULL
+WHERE sqlc.narg(competition_id)::UUID IS NULL OR p.competition_id = sqlc.narg(competition_id)::UUID
+ORDER BY p.user_id, p.competition_id, p.position;
@@ -0,0 +1,33 @@
+-- name: LockRatingEventLog :exec
+-- Holds the event log until the transaction ends, so that events are
+-- committed in the order of their ids.
+SELECT pg_advisory_xact_lock(hashtext('rating_events'));
+
+-- name: InsertRatingEvent :one
+INSERT INTO
+    rating_events (event, user_id, competition_id, act_id, data)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: ListRatingEventsAfterId :many
+SELECT * FROM rating_events
+WHERE id > sqlc.arg(id)
+    AND (sqlc.narg(competition_id)::uuid IS NULL OR competition_id = sqlc.narg(competition_id))
+    AND (sqlc.narg(act_id)::uuid IS NULL OR act_id = sqlc.narg(act_id))
+ORDER BY id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListRatingEventsAfterIdByGroupId :many
+SELECT e.* FROM rating_events e
+JOIN groups_users gu ON e.user_id = gu.user_id
+WHERE e.id > sqlc.arg(id)
+    AND gu.group_id = sqlc.arg(group_id)
+    AND (sqlc.narg(competition_id)::uuid IS NULL OR e.competition_id = sqlc.narg(competition_id))
+    AND (sqlc.narg(act_id)::uuid IS NULL OR e.act_id = sqlc.narg(act_id))
+ORDER BY e.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteRatingEventsByUserId :exec
+DELETE FROM rating_events WHERE user_id = $1;
+
+-- name: DeleteRatingEventsBefore :exec
+DELETE FROM rating_events WHERE created_at < $1;
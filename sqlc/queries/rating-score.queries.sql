@@ -0,0 +1,20 @@
+-- name: ListRatingScoresByRatingId :many
+SELECT rs.*, cc.name, cc.weight FROM rating_scores rs
+JOIN competition_categories cc ON rs.category_id = cc.id
+WHERE rs.rating_id = $1
+ORDER BY cc.position ASC;
+
+-- name: InsertRatingScore :exec
+INSERT INTO
+    rating_scores (rating_id, category_id, score)
+VALUES ($1, $2, $3);
+
+-- name: DeleteRatingScoresByRatingId :exec
+DELETE FROM rating_scores WHERE rating_id = $1;
+
+-- name: ListRatingScoresByCompetitionId :many
+SELECT rs.rating_id, rs.score FROM rating_scores rs
+JOIN competition_categories cc ON rs.category_id = cc.id
+JOIN ratings r ON rs.rating_id = r.id
+WHERE r.competition_id = $1 AND r.deleted_at IS NULL
+ORDER BY rs.rating_id, cc.position ASC;
@@ -1,25 +1,28 @@
 -- name: ListRatings :many
-SELECT * FROM ratings;
+SELECT * FROM ratings WHERE deleted_at IS NULL;
 
 -- name: ListRatingsByCompetitionId :many
-SELECT * FROM ratings WHERE competition_id = $1;
+SELECT * FROM ratings WHERE competition_id = $1 AND deleted_at IS NULL;
 
 -- name: ListRatingsByUserId :many
-SELECT * FROM ratings WHERE user_id = $1;
+SELECT * FROM ratings WHERE user_id = $1 AND deleted_at IS NULL;
 
 -- name: ListRatingsByActId :many
-SELECT * FROM ratings WHERE act_id = $1;
+SELECT * FROM ratings WHERE act_id = $1 AND deleted_at IS NULL;
 
 -- name: ListRatingsByCompetitionAndAcId :many
-SELECT * FROM ratings WHERE competition_id = $1 AND act_id = $2;
+SELECT * FROM ratings WHERE competition_id = $1 AND act_id = $2 AND deleted_at IS NULL;
 
 -- name: GetRatingById :one
-SELECT * FROM ratings WHERE id = $1 LIMIT 1;
+SELECT * FROM ratings WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetRatingByIdForUpdate :one
+SELECT * FROM ratings WHERE id = $1 AND deleted_at IS NULL LIMIT 1 FOR UPDATE;
 
 -- name: InsertRating :one
 INSERT INTO
-    ratings (song, singing, "show", looks, clothes, user_id, competition_id, act_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+    ratings (song, singing, "show", looks, clothes, total, user_id, competition_id, act_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
 
 -- name: UpdateRating :one
 UPDATE
@@ -30,9 +33,98 @@ SET
     "show" = $3,
     looks = $4,
     clothes = $5,
+    total = $6,
     updated_at = NOW()
 WHERE
-    id = $6 RETURNING *;
+    id = $7 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeleteRatingById :one
 DELETE FROM ratings WHERE id = $1 RETURNING *;
+
+-- name: DeleteRatingsByUserId :exec
+DELETE FROM ratings WHERE user_id = $1;
+
+-- name: SoftDeleteRatingById :one
+UPDATE ratings SET deleted_at = sqlc.arg(deleted_at) WHERE id = sqlc.arg(id) AND deleted_at IS NULL RETURNING *;
+
+-- name: GetDeletedRatingByIdForUpdate :one
+SELECT * FROM ratings WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1 FOR UPDATE;
+
+-- name: RestoreRatingById :one
+UPDATE ratings SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING *;
+
+-- name: ListRestorableRatings :many
+-- Lists the ratings deleted together with a competition or an act whose
+-- competition and act both exist again and whose author has not rated the
+-- act again since.
+SELECT r.* FROM ratings r
+JOIN competitions c ON r.competition_id = c.id
+JOIN acts a ON r.act_id = a.id
+WHERE r.deleted_at = sqlc.arg(deleted_at)
+    AND c.deleted_at IS NULL
+    AND a.deleted_at IS NULL
+    AND (sqlc.narg(competition_id)::UUID IS NULL OR r.competition_id = sqlc.narg(competition_id)::UUID)
+    AND (sqlc.narg(act_id)::UUID IS NULL OR r.act_id = sqlc.narg(act_id)::UUID)
+    AND NOT EXISTS (
+        SELECT 1 FROM ratings o
+        WHERE o.user_id = r.user_id
+            AND o.act_id = r.act_id
+            AND o.competition_id = r.competition_id
+            AND o.deleted_at IS NULL
+    );
+
+-- name: HasRatingsByCompetitionId :one
+SELECT EXISTS (
+    SELECT 1 FROM ratings WHERE competition_id = $1 AND deleted_at IS NULL
+);
+
+-- name: HasRatingsByActId :one
+SELECT EXISTS (
+    SELECT 1 FROM ratings WHERE act_id = $1 AND deleted_at IS NULL
+);
+
+-- name: PurgeDeletedRatingsByCompetitionId :exec
+DELETE FROM ratings WHERE competition_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeDeletedRatingsByActId :exec
+DELETE FROM ratings WHERE act_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: ListRatingsByCompetitionAndGroupId :many
+SELECT r.* FROM ratings r
+JOIN groups_users gu ON r.user_id = gu.user_id
+WHERE r.competition_id = $1 AND gu.group_id = $2 AND r.deleted_at IS NULL;
+
+-- name: CountRatingsByCompetitionId :one
+-- Counts deleted ratings too, they keep their scores for a restore.
+SELECT COUNT(*) FROM ratings WHERE competition_id = $1;
+
+-- name: LockRatings :exec
+LOCK TABLE ratings IN SHARE MODE;
+
+-- name: ListOverlappingRatingsByUserId :many
+SELECT o.* FROM ratings o
+JOIN ratings m ON m.competition_id = o.competition_id AND m.act_id = o.act_id
+WHERE m.user_id = $1 AND o.user_id <> $1 AND m.deleted_at IS NULL AND o.deleted_at IS NULL;
+
+-- name: ListRatingsPage :many
+SELECT * FROM ratings
+WHERE
+    deleted_at IS NULL
+    AND (sqlc.narg(competition_id)::UUID IS NULL OR competition_id = sqlc.narg(competition_id)::UUID)
+    AND (sqlc.narg(act_id)::UUID IS NULL OR act_id = sqlc.narg(act_id)::UUID)
+    AND (sqlc.narg(user_id)::UUID IS NULL OR user_id = sqlc.narg(user_id)::UUID)
+    AND (
+        sqlc.narg(after_id)::UUID IS NULL
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'total' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (total, id) > (sqlc.narg(after_int)::INT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'total' AND sqlc.arg(sort_desc)::BOOLEAN AND (total, id) < (sqlc.narg(after_int)::INT, sqlc.narg(after_id)::UUID))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'total' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN total END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'total' AND sqlc.arg(sort_desc)::BOOLEAN THEN total END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::BOOLEAN THEN id END DESC,
+    id ASC
+LIMIT sqlc.arg(row_limit)::INT;
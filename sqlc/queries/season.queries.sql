@@ -0,0 +1,57 @@
+-- name: ListSeasons :many
+SELECT * FROM seasons ORDER BY year DESC, name ASC;
+
+-- name: GetSeasonById :one
+SELECT * FROM seasons WHERE id = $1 LIMIT 1;
+
+-- name: InsertSeason :one
+INSERT INTO
+    seasons (name, year)
+VALUES ($1, $2) RETURNING *;
+
+-- name: UpdateSeason :one
+UPDATE
+    seasons
+SET
+    name = $1,
+    year = $2,
+    updated_at = NOW()
+WHERE
+    id = $3 RETURNING *;
+
+-- name: DeleteSeasonById :one
+DELETE FROM seasons WHERE id = $1 RETURNING *;
+
+-- name: ComputeUserStatsBySeasonId :many
+SELECT
+    r.user_id,
+    ROUND(AVG(r.total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings r
+JOIN competitions c ON r.competition_id = c.id
+WHERE c.season_id = $1 AND r.deleted_at IS NULL AND c.deleted_at IS NULL
+GROUP BY r.user_id
+ORDER BY rating_avg DESC, r.user_id;
+
+-- name: ComputeGlobalStatsBySeasonId :one
+SELECT
+    ROUND(AVG(r.total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings r
+JOIN competitions c ON r.competition_id = c.id
+WHERE c.season_id = $1 AND r.deleted_at IS NULL AND c.deleted_at IS NULL;
+
+-- name: ListSemiFinalActsBySeasonId :many
+SELECT
+    c.id AS competition_id,
+    sqlc.embed(a),
+    EXISTS (
+        SELECT 1 FROM qualifications q
+        JOIN competitions t ON q.target_competition_id = t.id
+        WHERE q.competition_id = c.id AND q.act_id = ca.act_id AND t.deleted_at IS NULL
+    ) AS qualified
+FROM competitions c
+JOIN competitions_acts ca ON c.id = ca.competition_id
+JOIN acts a ON ca.act_id = a.id
+WHERE c.season_id = $1 AND c.heat = 'HEAT_SEMI_FINAL' AND c.deleted_at IS NULL AND a.deleted_at IS NULL
+ORDER BY c.start_time ASC, ca.order ASC;
@@ -0,0 +1,18 @@
+-- name: ListUserErasures :many
+SELECT * FROM user_erasures ORDER BY created_at DESC;
+
+-- name: InsertUserErasure :one
+INSERT INTO
+    user_erasures (user_id, requested_by, deleted_ratings, transferred_groups, deleted_groups)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CompleteUserErasure :one
+UPDATE
+    user_erasures
+SET
+    deleted_images = $2,
+    clerk_unlinked = $3,
+    error = $4,
+    completed_at = NOW()
+WHERE
+    id = $1 RETURNING *;
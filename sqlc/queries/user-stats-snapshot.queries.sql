@@ -0,0 +1,15 @@
+-- name: ListUserStatsSnapshotsByUserId :many
+SELECT * FROM user_stats_snapshots WHERE user_id = $1 ORDER BY snapshot_date ASC;
+
+-- name: UpsertUserStatsSnapshots :exec
+INSERT INTO user_stats_snapshots (user_id, snapshot_date, rating_avg, rating_count, global_rating_avg)
+SELECT us.user_id, CURRENT_DATE, us.rating_avg, us.rating_count, gs.rating_avg
+FROM user_stats us
+LEFT JOIN global_stats gs ON gs.id
+WHERE sqlc.narg(user_id)::UUID IS NULL OR us.user_id = sqlc.narg(user_id)
+ON CONFLICT (user_id, snapshot_date) DO UPDATE
+SET
+    rating_avg = EXCLUDED.rating_avg,
+    rating_count = EXCLUDED.rating_count,
+    global_rating_avg = EXCLUDED.global_rating_avg,
+    updated_at = NOW();
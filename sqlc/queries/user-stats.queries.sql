@@ -17,4 +17,50 @@ SET
     rating_avg = $2,
     rating_count = $3,
     updated_at = NOW()
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ComputeUserStatsFromRatings :many
+SELECT
+    user_id,
+    ROUND(AVG(total), 2)::DECIMAL(6,2) AS rating_avg,
+    COUNT(*)::INT AS rating_count
+FROM ratings
+WHERE deleted_at IS NULL
+GROUP BY user_id
+ORDER BY user_id;
+
+-- name: DeleteUserStatsWithoutRatings :many
+DELETE FROM user_stats us
+WHERE NOT EXISTS (SELECT 1 FROM ratings r WHERE r.user_id = us.user_id AND r.deleted_at IS NULL)
+RETURNING *;
+
+-- name: GetStatsByUserIdForUpdate :one
+SELECT * FROM user_stats WHERE user_id = $1 LIMIT 1 FOR UPDATE;
+
+-- name: InsertEmptyUserStats :exec
+INSERT INTO user_stats (user_id, rating_avg, rating_count)
+VALUES ($1, 0, 0)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: ListUserStatsPage :many
+SELECT * FROM user_stats
+WHERE
+    (sqlc.narg(min_rating_count)::INT IS NULL OR rating_count >= sqlc.narg(min_rating_count)::INT)
+    AND (
+        sqlc.narg(after_id)::UUID IS NULL
+        OR (sqlc.arg(sort_by)::TEXT = 'ratingAvg' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (COALESCE(rating_avg, 0), user_id) > (sqlc.narg(after_numeric)::NUMERIC, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'ratingAvg' AND sqlc.arg(sort_desc)::BOOLEAN AND (COALESCE(rating_avg, 0), user_id) < (sqlc.narg(after_numeric)::NUMERIC, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'ratingCount' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (COALESCE(rating_count, 0), user_id) > (sqlc.narg(after_int)::INT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'ratingCount' AND sqlc.arg(sort_desc)::BOOLEAN AND (COALESCE(rating_count, 0), user_id) < (sqlc.narg(after_int)::INT, sqlc.narg(after_id)::UUID))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'ratingAvg' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN COALESCE(rating_avg, 0) END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'ratingAvg' AND sqlc.arg(sort_desc)::BOOLEAN THEN COALESCE(rating_avg, 0) END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'ratingCount' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN COALESCE(rating_count, 0) END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'ratingCount' AND sqlc.arg(sort_desc)::BOOLEAN THEN COALESCE(rating_count, 0) END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::BOOLEAN THEN user_id END DESC,
+    user_id ASC
+LIMIT sqlc.arg(row_limit)::INT;
+
+-- name: DeleteUserStatsByUserId :exec
+DELETE FROM user_stats WHERE user_id = $1;
@@ -4,13 +4,13 @@ SELECT * FROM users;
 -- name: ListUsersByCompetitionId :many
 SELECT u.* FROM users u
 JOIN ratings r ON u.id = r.user_id
-WHERE r.competition_id = $1;
+WHERE r.competition_id = $1 AND r.deleted_at IS NULL;
 
 -- name: ListUsersByActId :many
 SELECT u.* FROM users u
 JOIN ratings r ON u.id = r.user_id
 JOIN acts a ON r.act_id = a.id
-WHERE a.id = $1;
+WHERE a.id = $1 AND r.deleted_at IS NULL;
 
 -- name: GetUserById :one
 SELECT * FROM users WHERE id = $1 LIMIT 1;
@@ -18,6 +18,9 @@ SELECT * FROM users WHERE id = $1 LIMIT 1;
 -- name: GetUserBySub :one
 SELECT * FROM users WHERE sub = $1 LIMIT 1;
 
+-- name: GetUserByIdForUpdate :one
+SELECT * FROM users WHERE id = $1 LIMIT 1 FOR UPDATE;
+
 -- name: InsertUser :one
 INSERT INTO
     users (sub, email, firstname, lastname, image_url)
@@ -43,4 +46,45 @@ SET
     image_url = $2,
     updated_at = NOW()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+-- name: ListUsersByGroupId :many
+SELECT u.* FROM users u
+JOIN groups_users gu ON u.id = gu.user_id
+WHERE gu.group_id = $1;
+
+-- name: ListUsersByCompetitionAndGroupId :many
+SELECT DISTINCT u.* FROM users u
+JOIN ratings r ON u.id = r.user_id
+JOIN groups_users gu ON u.id = gu.user_id
+WHERE r.competition_id = $1 AND gu.group_id = $2 AND r.deleted_at IS NULL;
+
+-- name: ListUsersByIds :many
+SELECT * FROM users WHERE id = ANY(sqlc.arg(ids)::UUID[]);
+
+-- name: ListUsersPage :many
+SELECT * FROM users
+WHERE
+    (
+        sqlc.narg(search)::TEXT IS NULL
+        OR firstname ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+        OR lastname ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+    )
+    AND (
+        sqlc.narg(after_id)::UUID IS NULL
+        OR (sqlc.arg(sort_by)::TEXT = 'firstname' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (firstname, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'firstname' AND sqlc.arg(sort_desc)::BOOLEAN AND (firstname, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'lastname' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (lastname, id) > (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'lastname' AND sqlc.arg(sort_desc)::BOOLEAN AND (lastname, id) < (sqlc.narg(after_text)::TEXT, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+        OR (sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN AND (created_at, id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::UUID))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'firstname' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN firstname END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'firstname' AND sqlc.arg(sort_desc)::BOOLEAN THEN firstname END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'lastname' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN lastname END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'lastname' AND sqlc.arg(sort_desc)::BOOLEAN THEN lastname END DESC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND NOT sqlc.arg(sort_desc)::BOOLEAN THEN created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::TEXT = 'createdAt' AND sqlc.arg(sort_desc)::BOOLEAN THEN created_at END DESC,
+    CASE WHEN sqlc.arg(sort_desc)::BOOLEAN THEN id END DESC,
+    id ASC
+LIMIT sqlc.arg(row_limit)::INT;
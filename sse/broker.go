@@ -1,10 +1,85 @@
 package sse
 
+// Topic filters the messages a subscription receives. Subscriptions only
+// receive messages of their group, or messages without a group if they
+// are not subscribed to a group, unless the message is for all groups.
+// Empty competition and act ids match messages of any competition and act.
+type Topic struct {
+	GroupId       string `json:"group_id,omitempty"`
+	CompetitionId string `json:"competition_id,omitempty"`
+	ActId         string `json:"act_id,omitempty"`
+	// AllGroups delivers a message to the subscriptions of every group
+	// and to those without a group. It is meant for events of the
+	// competition itself, which are the same for everyone.
+	AllGroups bool `json:"all_groups,omitempty"`
+}
+
+// Matches reports whether a message of the given topic
+// is delivered to subscriptions of this topic.
+func (t Topic) Matches(message Topic) bool {
+	if !message.AllGroups && t.GroupId != message.GroupId {
+		return false
+	}
+
+	if t.CompetitionId != "" && t.CompetitionId != message.CompetitionId {
+		return false
+	}
+
+	if t.ActId != "" && t.ActId != message.ActId {
+		return false
+	}
+
+	return true
+}
+
+// SlowConsumerPolicy decides what happens to a subscription
+// whose buffer is full when a new event is dispatched.
+type SlowConsumerPolicy int
+
+const (
+	// DisconnectSlowConsumer drops the event and closes the subscription.
+	// Clients reconnect and replay the missed events via Last-Event-ID.
+	DisconnectSlowConsumer SlowConsumerPolicy = iota
+	// DropEvent drops the event and keeps the subscription open.
+	DropEvent
+)
+
+type BrokerOptions struct {
+	// BufferSize is the number of events buffered per subscription.
+	BufferSize int
+	// SlowConsumerPolicy is applied when a subscription's buffer is full.
+	SlowConsumerPolicy SlowConsumerPolicy
+}
+
+func DefaultBrokerOptions() BrokerOptions {
+	return BrokerOptions{
+		BufferSize:         64,
+		SlowConsumerPolicy: DisconnectSlowConsumer,
+	}
+}
+
+// Subscription receives the events of a topic. The events channel
+// is closed when the subscription is removed from the broker.
+type Subscription struct {
+	topic  Topic
+	userId string
+	events chan Event
+	// closed is only accessed in the broker's goroutine.
+	closed bool
+}
+
+// Events returns the channel the subscription's events are sent to.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
 type Broker struct {
-	// users is a map where the key is the user id
-	// and the value is a slice of channels to connections
-	// for that user id
-	users map[string][]chan Event
+	// topics is a map where the key is the topic
+	// and the value is a slice of subscriptions
+	// to that topic
+	topics map[Topic][]*Subscription
+
+	options BrokerOptions
 
 	// actions is a channel of functions to call
 	// in the broker's goroutine. The broker executes
@@ -22,65 +97,99 @@ func (b *Broker) Run() {
 }
 
 func NewBroker() *Broker {
+	return NewBrokerWithOptions(DefaultBrokerOptions())
+}
+
+func NewBrokerWithOptions(options BrokerOptions) *Broker {
 	b := &Broker{
-		users:   make(map[string][]chan Event),
+		topics:  make(map[Topic][]*Subscription),
+		options: options,
 		actions: make(chan func()),
 	}
 	go b.Run()
 	return b
 }
 
-// AddUserChan adds a channel for user with given id.
-func (b *Broker) AddUserChan(id string, ch chan Event) {
+// Subscribe adds a subscription for user with given id to the given topic.
+func (b *Broker) Subscribe(topic Topic, userId string) *Subscription {
+	s := &Subscription{
+		topic:  topic,
+		userId: userId,
+		events: make(chan Event, b.options.BufferSize),
+	}
+
 	b.actions <- func() {
-		b.users[id] = append(b.users[id], ch)
+		b.topics[topic] = append(b.topics[topic], s)
 	}
-}
 
-// RemoveUserchan removes a channel for a user with the given id.
-func (b *Broker) RemoveUserChan(id string, ch chan Event) {
-	// The broker may be trying to send to
-	// ch, but nothing is receiving. Pump ch
-	// to prevent broker from getting stuck.
-	go func() {
-		for range ch {
-		}
-	}()
+	return s
+}
 
+// Unsubscribe removes the subscription from the broker. It is safe to call
+// for subscriptions that were already disconnected by the broker.
+func (b *Broker) Unsubscribe(s *Subscription) {
 	b.actions <- func() {
-		chs := b.users[id]
-		i := 0
-		for _, c := range chs {
-			if c != ch {
-				chs[i] = c
-				i = i + 1
-			}
-		}
-		if i == 0 {
-			delete(b.users, id)
-		} else {
-			b.users[id] = chs[:i]
+		b.remove(s)
+	}
+}
+
+// remove must be called in the broker's goroutine.
+func (b *Broker) remove(s *Subscription) {
+	subs := b.topics[s.topic]
+	i := 0
+	for _, sub := range subs {
+		if sub != s {
+			subs[i] = sub
+			i = i + 1
 		}
-		// Close channel to break loop at beginning
-		// of removeUserChan.
-		// This must be done in broker goroutine
-		// to ensure that broker does not send to
-		// closed goroutine.
-		close(ch)
+	}
+	if i == 0 {
+		delete(b.topics, s.topic)
+	} else {
+		b.topics[s.topic] = subs[:i]
+	}
+
+	// Close channel in the broker goroutine to
+	// ensure that broker does not send to a
+	// closed channel.
+	if !s.closed {
+		close(s.events)
+		s.closed = true
 	}
 }
 
-// BroadcastEvent sends a message to all users except the source user
-func (b *Broker) BroadcastEvent(sourceUserId string, event Event) {
+// Dispatch sends the message to all subscriptions of matching
+// topics except the ones of the source user. It never blocks on
+// a subscription, subscriptions with a full buffer are handled
+// according to the broker's SlowConsumerPolicy.
+func (b *Broker) Dispatch(message Message) {
 	b.actions <- func() {
-		for userId, chs := range b.users {
-			if userId == sourceUserId {
+		var slowConsumers []*Subscription
+
+		for topic, subs := range b.topics {
+			if !topic.Matches(message.Topic) {
 				continue
 			}
 
-			for _, ch := range chs {
-				ch <- event
+			for _, s := range subs {
+				if s.userId == message.SourceUserId {
+					continue
+				}
+
+				select {
+				case s.events <- message.Event:
+				default:
+					droppedEventsCounter.Inc()
+					if b.options.SlowConsumerPolicy == DisconnectSlowConsumer {
+						slowConsumers = append(slowConsumers, s)
+					}
+				}
 			}
 		}
+
+		for _, s := range slowConsumers {
+			disconnectedSubscribersCounter.Inc()
+			b.remove(s)
+		}
 	}
 }
@@ -0,0 +1,96 @@
+package sse
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// flush waits until the broker has executed all previously queued actions.
+func flush(b *Broker) {
+	done := make(chan struct{})
+	b.actions <- func() { close(done) }
+	<-done
+}
+
+func newTestEvent(t *testing.T, id int) Event {
+	t.Helper()
+
+	event, err := NewEvent(EventOptions{ID: fmt.Sprintf("%d", id), Event: "createRating", Data: "data"})
+	assert.NoError(t, err)
+
+	return event
+}
+
+func TestBrokerDisconnectsSubscriberThatNeverReads(t *testing.T) {
+	b := NewBrokerWithOptions(BrokerOptions{BufferSize: 2, SlowConsumerPolicy: DisconnectSlowConsumer})
+	stalled := b.Subscribe(Topic{}, "stalled")
+	flush(b)
+
+	dropped := testutil.ToFloat64(droppedEventsCounter)
+	disconnected := testutil.ToFloat64(disconnectedSubscribersCounter)
+
+	for i := 1; i <= 5; i++ {
+		b.Dispatch(Message{SourceUserId: "source", Event: newTestEvent(t, i)})
+	}
+	flush(b)
+
+	var received []Event
+	for e := range stalled.Events() {
+		received = append(received, e)
+	}
+
+	assert.Equal(t, []Event{newTestEvent(t, 1), newTestEvent(t, 2)}, received, "buffered events are delivered before the channel is closed")
+	assert.Equal(t, dropped+1, testutil.ToFloat64(droppedEventsCounter))
+	assert.Equal(t, disconnected+1, testutil.ToFloat64(disconnectedSubscribersCounter))
+
+	// Unsubscribing a disconnected subscription must not panic.
+	b.Unsubscribe(stalled)
+	flush(b)
+}
+
+func TestBrokerDropsEventsForSubscriberThatNeverReads(t *testing.T) {
+	b := NewBrokerWithOptions(BrokerOptions{BufferSize: 2, SlowConsumerPolicy: DropEvent})
+	stalled := b.Subscribe(Topic{}, "stalled")
+	flush(b)
+
+	dropped := testutil.ToFloat64(droppedEventsCounter)
+	disconnected := testutil.ToFloat64(disconnectedSubscribersCounter)
+
+	for i := 1; i <= 5; i++ {
+		b.Dispatch(Message{SourceUserId: "source", Event: newTestEvent(t, i)})
+	}
+	flush(b)
+
+	assert.Equal(t, dropped+3, testutil.ToFloat64(droppedEventsCounter))
+	assert.Equal(t, disconnected, testutil.ToFloat64(disconnectedSubscribersCounter))
+
+	assert.Equal(t, newTestEvent(t, 1), <-stalled.Events())
+	assert.Equal(t, newTestEvent(t, 2), <-stalled.Events())
+
+	b.Dispatch(Message{SourceUserId: "source", Event: newTestEvent(t, 6)})
+	assert.Equal(t, newTestEvent(t, 6), <-stalled.Events(), "subscription stays open once it catches up")
+
+	b.Unsubscribe(stalled)
+	flush(b)
+}
+
+func TestBrokerIsNotBlockedBySubscriberThatNeverReads(t *testing.T) {
+	b := NewBrokerWithOptions(BrokerOptions{BufferSize: 1, SlowConsumerPolicy: DropEvent})
+	b.Subscribe(Topic{}, "stalled")
+	reader := b.Subscribe(Topic{}, "reader")
+
+	for i := 1; i <= 100; i++ {
+		b.Dispatch(Message{SourceUserId: "source", Event: newTestEvent(t, i)})
+
+		select {
+		case e := <-reader.Events():
+			assert.Equal(t, newTestEvent(t, i), e)
+		case <-time.After(time.Second):
+			t.Fatalf("reader did not receive event %d", i)
+		}
+	}
+}
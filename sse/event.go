@@ -47,8 +47,12 @@ func (ev *Event) MarshalTo(w io.Writer) error {
 	}
 
 	if len(ev.Data) > 0 {
-		if _, err := fmt.Fprintf(w, "id: %s\n", ev.ID); err != nil {
-			return err
+		// An empty id field resets the client's last event ID,
+		// which would break replaying missed events on reconnect.
+		if len(ev.ID) > 0 {
+			if _, err := fmt.Fprintf(w, "id: %s\n", ev.ID); err != nil {
+				return err
+			}
 		}
 
 		sd := bytes.Split(ev.Data, []byte("\n"))
@@ -84,6 +88,18 @@ func (ev *Event) MarshalTo(w io.Writer) error {
 	return nil
 }
 
+// Sequence returns the ID of the event as a sequence number of the
+// event log. Events that are not part of the log, like pings, have
+// no sequence number.
+func (ev *Event) Sequence() (int64, bool) {
+	sequence, err := strconv.ParseInt(string(ev.ID), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return sequence, true
+}
+
 // NewEvent creates a new Event
 func NewEvent(publicEvent EventOptions) (Event, error) {
 	privateEvent := Event{}
@@ -0,0 +1,58 @@
+package sse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  EventOptions
+		expected string
+	}{
+		{
+			name:     "Event with id",
+			options:  EventOptions{ID: "42", Event: "createRating", Data: "data", Retry: 10000},
+			expected: "id: 42\ndata: \"data\"\nevent: createRating\nretry: 10000\n\n",
+		},
+		{
+			name:     "Event without id does not reset last event id",
+			options:  EventOptions{Event: "ping", Data: "keep-alive", Retry: 10000},
+			expected: "data: \"keep-alive\"\nevent: ping\nretry: 10000\n\n",
+		},
+		{
+			name:     "Event without data",
+			options:  EventOptions{ID: "42", Event: "ping"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := NewEvent(tt.options)
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			assert.NoError(t, event.MarshalTo(&buf))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestSequence(t *testing.T) {
+	event, err := NewEvent(EventOptions{ID: "42", Data: "data"})
+	assert.NoError(t, err)
+
+	sequence, ok := event.Sequence()
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), sequence)
+
+	event, err = NewEvent(EventOptions{ID: "system", Data: "data"})
+	assert.NoError(t, err)
+
+	_, ok = event.Sequence()
+	assert.False(t, ok)
+}
@@ -0,0 +1,18 @@
+package sse
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	droppedEventsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sse_dropped_events_total",
+		Help: "Number of events dropped because a subscriber did not keep up.",
+	})
+
+	disconnectedSubscribersCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sse_disconnected_subscribers_total",
+		Help: "Number of subscribers disconnected because they did not keep up.",
+	})
+)
@@ -0,0 +1,100 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// notifyPayloadLimit is the maximum payload size of pg_notify.
+	notifyPayloadLimit = 8000
+	listenRetryDelay   = 5 * time.Second
+)
+
+// PostgresPublisher publishes messages with pg_notify and dispatches every
+// message it receives via LISTEN to the local broker. Running one on every
+// instance fans out every event to the subscribers of all instances.
+type PostgresPublisher struct {
+	pool    *pgxpool.Pool
+	broker  *Broker
+	channel string
+}
+
+func NewPostgresPublisher(pool *pgxpool.Pool, broker *Broker, channel string) *PostgresPublisher {
+	return &PostgresPublisher{
+		pool:    pool,
+		broker:  broker,
+		channel: channel,
+	}
+}
+
+// Publish sends the message to all instances including this one.
+func (p *PostgresPublisher) Publish(ctx context.Context, message Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) >= notifyPayloadLimit {
+		return fmt.Errorf("event payload of %d bytes exceeds the notify limit", len(payload))
+	}
+
+	_, err = p.pool.Exec(ctx, "SELECT pg_notify($1, $2)", p.channel, string(payload))
+	return err
+}
+
+// Listen executes in a goroutine. It dispatches all notifications to the
+// local broker and reconnects until the given context is done.
+func (p *PostgresPublisher) Listen(ctx context.Context) {
+	for {
+		err := p.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Error().Err(err).Msg("listening for events failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (p *PostgresPublisher) listen(ctx context.Context) error {
+	poolConn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection stays in LISTEN mode for its whole lifetime,
+	// so it must not be returned to the pool.
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var message Message
+		if err := json.Unmarshal([]byte(notification.Payload), &message); err != nil {
+			log.Error().Err(err).Msg("could not decode event notification")
+			continue
+		}
+
+		p.broker.Dispatch(message)
+	}
+}
@@ -0,0 +1,24 @@
+package sse
+
+import "context"
+
+// Message is an event that is broadcast to the subscribers of
+// matching topics on every instance.
+type Message struct {
+	Topic
+	SourceUserId string `json:"source_user_id"`
+	Event        Event  `json:"event"`
+}
+
+// Publisher distributes messages to the brokers of all instances.
+// The Broker itself is a Publisher that only knows the subscribers
+// of the current instance.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// Publish dispatches the message to the subscribers of this broker.
+func (b *Broker) Publish(ctx context.Context, message Message) error {
+	b.Dispatch(message)
+	return nil
+}
@@ -0,0 +1,113 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, s *Subscription) (Event, bool) {
+	t.Helper()
+
+	select {
+	case e := <-s.Events():
+		return e, true
+	case <-time.After(100 * time.Millisecond):
+		return Event{}, false
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	b := NewBroker()
+	source := b.Subscribe(Topic{}, "source")
+	other := b.Subscribe(Topic{}, "other")
+	groupMember := b.Subscribe(Topic{GroupId: "group"}, "member")
+
+	event, err := NewEvent(EventOptions{ID: "1", Event: "createRating", Data: "data"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), Message{SourceUserId: "source", Event: event}))
+
+	received, ok := receive(t, other)
+	assert.True(t, ok)
+	assert.Equal(t, event, received)
+
+	_, ok = receive(t, source)
+	assert.False(t, ok, "source user must not receive its own event")
+
+	_, ok = receive(t, groupMember)
+	assert.False(t, ok, "group subscribers only receive group messages")
+
+	assert.NoError(t, b.Publish(context.Background(), Message{Topic: Topic{GroupId: "group"}, SourceUserId: "source", Event: event}))
+
+	received, ok = receive(t, groupMember)
+	assert.True(t, ok)
+	assert.Equal(t, event, received)
+}
+
+func TestBrokerDispatchByTopic(t *testing.T) {
+	b := NewBroker()
+	all := b.Subscribe(Topic{}, "all")
+	competition := b.Subscribe(Topic{CompetitionId: "semi"}, "competition")
+	act := b.Subscribe(Topic{CompetitionId: "semi", ActId: "act"}, "act")
+	otherCompetition := b.Subscribe(Topic{CompetitionId: "final"}, "otherCompetition")
+
+	event, err := NewEvent(EventOptions{ID: "1", Event: "createRating", Data: "data"})
+	assert.NoError(t, err)
+
+	b.Dispatch(Message{Topic: Topic{CompetitionId: "semi", ActId: "other"}, SourceUserId: "source", Event: event})
+
+	_, ok := receive(t, all)
+	assert.True(t, ok)
+
+	_, ok = receive(t, competition)
+	assert.True(t, ok)
+
+	_, ok = receive(t, act)
+	assert.False(t, ok, "act subscribers only receive events of their act")
+
+	_, ok = receive(t, otherCompetition)
+	assert.False(t, ok, "competition subscribers only receive events of their competition")
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	event, err := NewEvent(EventOptions{ID: "1", Event: "createRating", Data: map[string]string{"id": "rating"}, Retry: 10000})
+	assert.NoError(t, err)
+
+	message := Message{Topic: Topic{GroupId: "group", CompetitionId: "competition"}, SourceUserId: "user", Event: event}
+	payload, err := json.Marshal(message)
+	assert.NoError(t, err)
+
+	var decoded Message
+	assert.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, message, decoded)
+}
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		topic    Topic
+		message  Topic
+		expected bool
+	}{
+		{"Empty topic matches any competition", Topic{}, Topic{CompetitionId: "c", ActId: "a"}, true},
+		{"Empty topic does not match group messages", Topic{}, Topic{GroupId: "g"}, false},
+		{"Group topic does not match global messages", Topic{GroupId: "g"}, Topic{}, false},
+		{"Group topic matches messages of its group", Topic{GroupId: "g"}, Topic{GroupId: "g", CompetitionId: "c"}, true},
+		{"Competition topic matches acts of its competition", Topic{CompetitionId: "c"}, Topic{CompetitionId: "c", ActId: "a"}, true},
+		{"Competition topic does not match other competitions", Topic{CompetitionId: "c"}, Topic{CompetitionId: "d"}, false},
+		{"Act topic does not match other acts", Topic{CompetitionId: "c", ActId: "a"}, Topic{CompetitionId: "c", ActId: "b"}, false},
+		{"Group topic matches messages for all groups", Topic{GroupId: "g", CompetitionId: "c"}, Topic{CompetitionId: "c", AllGroups: true}, true},
+		{"Empty topic matches messages for all groups", Topic{}, Topic{CompetitionId: "c", AllGroups: true}, true},
+		{"Messages for all groups keep the competition filter", Topic{GroupId: "g", CompetitionId: "c"}, Topic{CompetitionId: "d", AllGroups: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.topic.Matches(tt.message))
+		})
+	}
+}
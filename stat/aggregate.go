@@ -0,0 +1,166 @@
+package stat
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// aggregateScore is a score of a rating in one category of the act and
+// competition stats, the total is kept in the empty category.
+type aggregateScore struct {
+	Category string
+	Score    int32
+}
+
+// aggregateScores returns the scores a rating contributes to the act and
+// competition stats.
+func aggregateScores(rating *pb.RatingResponse, scores []db.ListRatingScoresByRatingIdRow) []aggregateScore {
+	aggregates := make([]aggregateScore, 0, len(scores)+1)
+	aggregates = append(aggregates, aggregateScore{Score: rating.Total})
+
+	for _, score := range scores {
+		aggregates = append(aggregates, aggregateScore{Category: score.Name, Score: score.Score})
+	}
+
+	return aggregates
+}
+
+// addToAggregateStats adds the scores of the rating to the stats rows of
+// its act and competition. The rows keep the sums of the scores and of
+// their squares, so the average and variance are updated in place.
+func (s *Service) addToAggregateStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse, scores []db.ListRatingScoresByRatingIdRow) error {
+	competitionId, actId, err := aggregateIds(rating)
+	if err != nil {
+		return err
+	}
+
+	for _, score := range aggregateScores(rating, scores) {
+		if err := queries.AddToActStats(ctx, db.AddToActStatsParams{
+			CompetitionID: competitionId,
+			ActID:         actId,
+			Category:      score.Category,
+			Score:         score.Score,
+		}); err != nil {
+			return err
+		}
+
+		if err := queries.AddToCompetitionStats(ctx, db.AddToCompetitionStatsParams{
+			CompetitionID: competitionId,
+			Category:      score.Category,
+			Score:         score.Score,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeFromAggregateStats removes the scores of the rating from the stats
+// rows of its act and competition. A removed score that was the act's min
+// or max is looked up again among the act's remaining ratings, the
+// competition's min and max are taken from its acts. Rows without ratings
+// are deleted. The rating has to be excluded from the live ratings already.
+func (s *Service) removeFromAggregateStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse, scores []db.ListRatingScoresByRatingIdRow) error {
+	competitionId, actId, err := aggregateIds(rating)
+	if err != nil {
+		return err
+	}
+
+	for _, score := range aggregateScores(rating, scores) {
+		actStats, err := queries.RemoveFromActStats(ctx, db.RemoveFromActStatsParams{
+			Score:         score.Score,
+			CompetitionID: competitionId,
+			ActID:         actId,
+			Category:      score.Category,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if actStats.RatingCount > 0 && (score.Score <= actStats.RatingMin || score.Score >= actStats.RatingMax) {
+			if err := queries.RefreshActStatsRange(ctx, db.RefreshActStatsRangeParams{
+				CompetitionID: competitionId,
+				ActID:         actId,
+				Category:      score.Category,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := queries.RemoveFromCompetitionStats(ctx, db.RemoveFromCompetitionStatsParams{
+			Score:         score.Score,
+			CompetitionID: competitionId,
+			Category:      score.Category,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := queries.DeleteEmptyActStats(ctx, db.DeleteEmptyActStatsParams{
+		CompetitionID: competitionId,
+		ActID:         actId,
+	}); err != nil {
+		return err
+	}
+
+	return queries.DeleteEmptyCompetitionStats(ctx, competitionId)
+}
+
+func listRatingScores(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) ([]db.ListRatingScoresByRatingIdRow, error) {
+	ratingId, err := mapper.FromProtoToDbId(rating.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return queries.ListRatingScoresByRatingId(ctx, ratingId)
+}
+
+func aggregateIds(rating *pb.RatingResponse) (pgtype.UUID, pgtype.UUID, error) {
+	competitionId, err := mapper.FromProtoToDbId(rating.CompetitionId)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, err
+	}
+
+	actId, err := mapper.FromProtoToDbId(rating.ActId)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, err
+	}
+
+	return competitionId, actId, nil
+}
+
+// rebuildAggregateStats rebuilds the act and competition stats of the
+// given act and competition from their ratings, invalid ids rebuild the
+// stats of all acts or competitions.
+func rebuildAggregateStats(ctx context.Context, queries *db.Queries, competitionId pgtype.UUID, actId pgtype.UUID) error {
+	if err := queries.UpsertActStatsFromRatings(ctx, db.UpsertActStatsFromRatingsParams{
+		CompetitionID: competitionId,
+		ActID:         actId,
+	}); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteActStatsWithoutRatings(ctx, db.DeleteActStatsWithoutRatingsParams{
+		CompetitionID: competitionId,
+		ActID:         actId,
+	}); err != nil {
+		return err
+	}
+
+	if err := queries.UpsertCompetitionStatsFromRatings(ctx, competitionId); err != nil {
+		return err
+	}
+
+	return queries.DeleteCompetitionStatsWithoutRatings(ctx, competitionId)
+}
@@ -0,0 +1,149 @@
+package stat
+
+import (
+	"context"
+	"sort"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+)
+
+// refreshCategoryStats rebuilds the per-category stats of the rating's
+// author from the author's ratings and shifts the global per-category
+// stats by the difference. The caller has to hold the lock on the author's
+// user stats row, which serializes the rating writes of the author.
+func (s *Service) refreshCategoryStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+	userId, err := mapper.FromProtoToDbId(rating.User.Id)
+	if err != nil {
+		return err
+	}
+
+	oldStats, err := queries.ListUserCategoryStatsByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	newStats, err := queries.ComputeUserCategoryStatsFromRatings(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, stats := range newStats {
+		if err := queries.UpsertUserCategoryStats(ctx, db.UpsertUserCategoryStatsParams{
+			UserID:     userId,
+			Category:   stats.Category,
+			Min:        stats.Min,
+			Max:        stats.Max,
+			ScoreSum:   stats.ScoreSum,
+			ScoreCount: stats.ScoreCount,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, delta := range categoryStatsDeltas(oldStats, newStats) {
+		if err := queries.AddToGlobalCategoryStats(ctx, delta); err != nil {
+			return err
+		}
+	}
+
+	for _, stats := range oldStats {
+		if hasCategoryStats(newStats, categoryKey{stats.Category, stats.Min, stats.Max}) {
+			continue
+		}
+
+		if err := queries.DeleteUserCategoryStats(ctx, db.DeleteUserCategoryStatsParams{
+			UserID:   userId,
+			Category: stats.Category,
+			Min:      stats.Min,
+			Max:      stats.Max,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// categoryKey identifies the stats of a category. Categories with the
+// same name but a different range are kept apart because their scores
+// cannot be averaged together.
+type categoryKey struct {
+	Category string
+	Min      int32
+	Max      int32
+}
+
+// categoryStatsDeltas returns the changes of the global per-category stats
+// when the user's old per-category stats are replaced by the new ones,
+// ordered by category. Categories that did not change are left out.
+func categoryStatsDeltas(oldStats []db.UserCategoryStat, newStats []db.ComputeUserCategoryStatsFromRatingsRow) []db.AddToGlobalCategoryStatsParams {
+	deltas := make(map[categoryKey]db.AddToGlobalCategoryStatsParams)
+	for _, stats := range oldStats {
+		key := categoryKey{stats.Category, stats.Min, stats.Max}
+		delta := deltas[key]
+		delta.ScoreSum -= stats.ScoreSum
+		delta.ScoreCount -= stats.ScoreCount
+		deltas[key] = delta
+	}
+
+	for _, stats := range newStats {
+		key := categoryKey{stats.Category, stats.Min, stats.Max}
+		delta := deltas[key]
+		delta.ScoreSum += stats.ScoreSum
+		delta.ScoreCount += stats.ScoreCount
+		deltas[key] = delta
+	}
+
+	changed := make([]db.AddToGlobalCategoryStatsParams, 0, len(deltas))
+	for key, delta := range deltas {
+		if delta.ScoreCount == 0 && delta.ScoreSum == 0 {
+			continue
+		}
+
+		delta.Category, delta.Min, delta.Max = key.Category, key.Min, key.Max
+		changed = append(changed, delta)
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		a, b := changed[i], changed[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+
+		if a.Min != b.Min {
+			return a.Min < b.Min
+		}
+
+		return a.Max < b.Max
+	})
+
+	return changed
+}
+
+func hasCategoryStats(stats []db.ComputeUserCategoryStatsFromRatingsRow, key categoryKey) bool {
+	for _, s := range stats {
+		if s.Category == key.Category && s.Min == key.Min && s.Max == key.Max {
+			return true
+		}
+	}
+
+	return false
+}
+
+func rebuildCategoryStats(ctx context.Context, queries *db.Queries) error {
+	if err := queries.DeleteAllUserCategoryStats(ctx); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteAllGlobalCategoryStats(ctx); err != nil {
+		return err
+	}
+
+	if err := queries.InsertAllUserCategoryStatsFromRatings(ctx); err != nil {
+		return err
+	}
+
+	return queries.InsertAllGlobalCategoryStatsFromRatings(ctx)
+}
@@ -0,0 +1,96 @@
+package stat
+
+import (
+	"testing"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryStatsDeltas(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldStats []db.UserCategoryStat
+		newStats []db.ComputeUserCategoryStatsFromRatingsRow
+		expected []db.AddToGlobalCategoryStatsParams
+	}{
+		{
+			name:     "First rating adds all categories",
+			oldStats: nil,
+			newStats: []db.ComputeUserCategoryStatsFromRatingsRow{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 12, ScoreCount: 1},
+				{Category: "show", Min: 1, Max: 15, ScoreSum: 8, ScoreCount: 1},
+			},
+			expected: []db.AddToGlobalCategoryStatsParams{
+				{Category: "show", Min: 1, Max: 15, ScoreSum: 8, ScoreCount: 1},
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 12, ScoreCount: 1},
+			},
+		},
+		{
+			name: "Updated rating shifts the sums only",
+			oldStats: []db.UserCategoryStat{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 20, ScoreCount: 2},
+				{Category: "show", Min: 1, Max: 15, ScoreSum: 10, ScoreCount: 2},
+			},
+			newStats: []db.ComputeUserCategoryStatsFromRatingsRow{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 25, ScoreCount: 2},
+				{Category: "show", Min: 1, Max: 15, ScoreSum: 10, ScoreCount: 2},
+			},
+			expected: []db.AddToGlobalCategoryStatsParams{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 5, ScoreCount: 0},
+			},
+		},
+		{
+			name: "Removed categories are subtracted",
+			oldStats: []db.UserCategoryStat{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 20, ScoreCount: 2},
+				{Category: "staging", Min: 1, Max: 10, ScoreSum: 7, ScoreCount: 1},
+			},
+			newStats: []db.ComputeUserCategoryStatsFromRatingsRow{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 20, ScoreCount: 2},
+			},
+			expected: []db.AddToGlobalCategoryStatsParams{
+				{Category: "staging", Min: 1, Max: 10, ScoreSum: -7, ScoreCount: -1},
+			},
+		},
+		{
+			name: "Categories with another range are kept apart",
+			oldStats: []db.UserCategoryStat{
+				{Category: "looks", Min: 1, Max: 15, ScoreSum: 14, ScoreCount: 1},
+			},
+			newStats: []db.ComputeUserCategoryStatsFromRatingsRow{
+				{Category: "looks", Min: 1, Max: 15, ScoreSum: 14, ScoreCount: 1},
+				{Category: "looks", Min: 1, Max: 10, ScoreSum: 9, ScoreCount: 1},
+			},
+			expected: []db.AddToGlobalCategoryStatsParams{
+				{Category: "looks", Min: 1, Max: 10, ScoreSum: 9, ScoreCount: 1},
+			},
+		},
+		{
+			name: "Last rating removes all categories",
+			oldStats: []db.UserCategoryStat{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 12, ScoreCount: 1},
+			},
+			newStats: nil,
+			expected: []db.AddToGlobalCategoryStatsParams{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: -12, ScoreCount: -1},
+			},
+		},
+		{
+			name: "Unchanged stats have no deltas",
+			oldStats: []db.UserCategoryStat{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 12, ScoreCount: 1},
+			},
+			newStats: []db.ComputeUserCategoryStatsFromRatingsRow{
+				{Category: "song", Min: 1, Max: 15, ScoreSum: 12, ScoreCount: 1},
+			},
+			expected: []db.AddToGlobalCategoryStatsParams{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, categoryStatsDeltas(tt.oldStats, tt.newStats))
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package stat
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Drift is the difference between the stored and the recomputed stats of
+// a single user or, if UserId is empty, of the global stats.
+type Drift struct {
+	UserId          string  `json:"user_id,omitempty"`
+	StoredAvg       float64 `json:"stored_avg"`
+	StoredCount     int32   `json:"stored_count"`
+	RecomputedAvg   float64 `json:"recomputed_avg"`
+	RecomputedCount int32   `json:"recomputed_count"`
+}
+
+type Report struct {
+	Applied      bool    `json:"applied"`
+	UsersChecked int32   `json:"users_checked"`
+	Drifts       []Drift `json:"drifts"`
+}
+
+// Recompute rebuilds user_stats and global_stats from the ratings and
+// reports every stats row that differed from its recomputed value. The act,
+// competition and category stats are rebuilt as well without reporting
+// drift. The ratings are locked against changes until the rebuild is
+// committed, a dry run only reports the drift and leaves the stats
+// untouched.
+func (s *Service) Recompute(ctx context.Context, dryRun bool) (*Report, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.queries.WithTx(tx)
+
+	if err := queries.LockRatings(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Applied: !dryRun, Drifts: make([]Drift, 0)}
+
+	if err := s.recomputeUserStats(ctx, queries, report); err != nil {
+		return nil, err
+	}
+
+	if err := s.recomputeGlobalStats(ctx, queries, report); err != nil {
+		return nil, err
+	}
+
+	if err := rebuildAggregateStats(ctx, queries, pgtype.UUID{}, pgtype.UUID{}); err != nil {
+		return nil, err
+	}
+
+	if err := rebuildCategoryStats(ctx, queries); err != nil {
+		return nil, err
+	}
+
+	if err := queries.UpsertUserStatsSnapshots(ctx, pgtype.UUID{}); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	return report, tx.Commit(ctx)
+}
+
+func (s *Service) recomputeUserStats(ctx context.Context, queries *db.Queries, report *Report) error {
+	storedStats, err := queries.ListUserStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[pgtype.UUID]db.UserStat, len(storedStats))
+	for _, userStats := range storedStats {
+		stored[userStats.UserID] = userStats
+	}
+
+	recomputedStats, err := queries.ComputeUserStatsFromRatings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, recomputed := range recomputedStats {
+		report.UsersChecked++
+
+		userId, err := mapper.FromDbToProtoId(recomputed.UserID)
+		if err != nil {
+			return err
+		}
+
+		drift := Drift{
+			UserId:          userId,
+			RecomputedAvg:   numericToFloat64(recomputed.RatingAvg),
+			RecomputedCount: recomputed.RatingCount,
+		}
+
+		if userStats, ok := stored[recomputed.UserID]; ok {
+			drift.StoredAvg = numericToFloat64(userStats.RatingAvg)
+			drift.StoredCount = userStats.RatingCount.Int32
+			delete(stored, recomputed.UserID)
+		}
+
+		if drift.hasDrifted() {
+			report.Drifts = append(report.Drifts, drift)
+		}
+
+		if _, err := queries.UpsertUserStats(ctx, db.UpsertUserStatsParams{
+			UserID:      recomputed.UserID,
+			RatingAvg:   recomputed.RatingAvg,
+			RatingCount: pgtype.Int4{Int32: recomputed.RatingCount, Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The remaining stats belong to users without any ratings.
+	for _, userStats := range stored {
+		report.UsersChecked++
+
+		userId, err := mapper.FromDbToProtoId(userStats.UserID)
+		if err != nil {
+			return err
+		}
+
+		drift := Drift{
+			UserId:      userId,
+			StoredAvg:   numericToFloat64(userStats.RatingAvg),
+			StoredCount: userStats.RatingCount.Int32,
+		}
+
+		if drift.hasDrifted() {
+			report.Drifts = append(report.Drifts, drift)
+		}
+	}
+
+	_, err = queries.DeleteUserStatsWithoutRatings(ctx)
+	return err
+}
+
+func (s *Service) recomputeGlobalStats(ctx context.Context, queries *db.Queries, report *Report) error {
+	recomputed, err := queries.ComputeGlobalStatsFromRatings(ctx)
+	if err != nil {
+		return err
+	}
+
+	drift := Drift{
+		RecomputedAvg:   numericToFloat64(recomputed.RatingAvg),
+		RecomputedCount: recomputed.RatingCount,
+	}
+
+	globalStats, err := queries.GetGlobalStats(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if err == nil {
+		drift.StoredAvg = numericToFloat64(globalStats.RatingAvg)
+		drift.StoredCount = globalStats.RatingCount.Int32
+	}
+
+	if drift.hasDrifted() {
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	_, err = queries.UpsertGlobalStats(ctx, db.UpsertGlobalStatsParams{
+		RatingAvg:   recomputed.RatingAvg,
+		RatingCount: pgtype.Int4{Int32: recomputed.RatingCount, Valid: true},
+	})
+	return err
+}
+
+// hasDrifted compares the averages with the precision they are stored
+// with.
+func (d Drift) hasDrifted() bool {
+	return d.StoredCount != d.RecomputedCount || math.Abs(d.StoredAvg-d.RecomputedAvg) >= 0.005
+}
+
+func numericToFloat64(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil {
+		return 0
+	}
+
+	return f.Float64
+}
@@ -0,0 +1,15 @@
+package stat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftHasDrifted(t *testing.T) {
+	assert.False(t, Drift{StoredAvg: 42.5, StoredCount: 3, RecomputedAvg: 42.5, RecomputedCount: 3}.hasDrifted())
+	assert.False(t, Drift{StoredAvg: 42.501, StoredCount: 3, RecomputedAvg: 42.5, RecomputedCount: 3}.hasDrifted())
+	assert.True(t, Drift{StoredAvg: 42.51, StoredCount: 3, RecomputedAvg: 42.5, RecomputedCount: 3}.hasDrifted())
+	assert.True(t, Drift{StoredAvg: 42.5, StoredCount: 4, RecomputedAvg: 42.5, RecomputedCount: 3}.hasDrifted())
+	assert.False(t, Drift{}.hasDrifted())
+}
@@ -23,193 +23,123 @@ func NewService(pool *pgxpool.Pool) *Service {
 	}
 }
 
-func (s *Service) AddRatingToStats(ctx context.Context, rating *pb.RatingResponse) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
+// AddRatingToStats adds the rating to the user, global, act and competition
+// stats. The stats are written with the given queries so that they commit
+// together with the rating write, the stats rows are locked until then so
+// that concurrent rating writes do not overwrite each other's averages.
+// The global stats row is shared by every rating write, so it is updated
+// atomically and last to hold its lock for as short as possible. The user
+// stats snapshot includes the global average and is therefore taken after.
+func (s *Service) AddRatingToStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+	if err := s.addToUserStats(ctx, queries, rating); err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
-		}
-	}()
-
-	queries := s.queries.WithTx(tx)
 
-	err = s.addToUserStats(ctx, queries, rating)
-	if err != nil {
+	if err := s.refreshCategoryStats(ctx, queries, rating); err != nil {
 		return err
 	}
 
-	return s.addToGlobalStats(ctx, queries, rating)
-}
-
-func (s *Service) UpdateRatingInStats(ctx context.Context, rating *pb.RatingResponse) error {
-	tx, err := s.pool.Begin(ctx)
+	scores, err := listRatingScores(ctx, queries, rating)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
-		}
-	}()
-
-	queries := s.queries.WithTx(tx)
 
-	err = s.updateUserStats(ctx, queries, rating)
-	if err != nil {
+	if err := s.addToAggregateStats(ctx, queries, rating, scores); err != nil {
 		return err
 	}
 
-	return s.updateGlobalStats(ctx, queries, rating)
-}
-
-func (s *Service) RemoveRatingFromStats(ctx context.Context, rating *pb.RatingResponse) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
+	if err := queries.AddToGlobalStats(ctx, rating.Total); err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		} else {
-			tx.Commit(ctx)
-		}
-	}()
 
-	queries := s.queries.WithTx(tx)
+	return s.snapshotUserStats(ctx, queries, rating)
+}
 
-	err = s.removeFromUserStats(ctx, queries, rating)
-	if err != nil {
+// UpdateRatingInStats replaces the old version of a rating with its new
+// version in the stats. The old scores are the category scores of the old
+// version, they have to be listed before the scores are replaced.
+func (s *Service) UpdateRatingInStats(ctx context.Context, queries *db.Queries, oldRating *pb.RatingResponse, oldScores []db.ListRatingScoresByRatingIdRow, rating *pb.RatingResponse) error {
+	if err := s.updateUserStats(ctx, queries, oldRating, rating); err != nil {
 		return err
 	}
 
-	return s.removeFromGlobalStats(ctx, queries, rating)
-}
-
-func (s *Service) addToUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
-	userId, err := mapper.FromProtoToDbId(rating.User.Id)
-	if err != nil {
+	if err := s.refreshCategoryStats(ctx, queries, rating); err != nil {
 		return err
 	}
 
-	userStats, err := queries.GetStatsByUserId(ctx, userId)
-	if errors.Is(err, pgx.ErrNoRows) {
-		upsertParams := mapper.FromRatingToDbUserStats(rating)
-		_, err = queries.UpsertUserStats(ctx, upsertParams)
-		if err != nil {
-			return err
-		}
-
-		return nil
+	if err := s.removeFromAggregateStats(ctx, queries, oldRating, oldScores); err != nil {
+		return err
 	}
 
+	scores, err := listRatingScores(ctx, queries, rating)
 	if err != nil {
 		return err
 	}
 
-	upsertParams, err := mapper.AddToUserStats(rating, userStats)
-	if err != nil {
+	if err := s.addToAggregateStats(ctx, queries, rating, scores); err != nil {
 		return err
 	}
 
-	_, err = queries.UpsertUserStats(ctx, upsertParams)
-	if err != nil {
+	if err := queries.UpdateInGlobalStats(ctx, db.UpdateInGlobalStatsParams{
+		OldTotal: oldRating.Total,
+		NewTotal: rating.Total,
+	}); err != nil {
 		return err
 	}
 
-	return nil
+	return s.snapshotUserStats(ctx, queries, rating)
 }
 
-func (s *Service) updateUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
-	userId, err := mapper.FromProtoToDbId(rating.User.Id)
-	if err != nil {
+// RemoveRatingFromStats removes a rating from the stats. The rating has to
+// be soft deleted already, its scores are still needed.
+func (s *Service) RemoveRatingFromStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+	if err := s.removeFromUserStats(ctx, queries, rating); err != nil {
 		return err
 	}
 
-	userStats, err := queries.GetStatsByUserId(ctx, userId)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil
-	}
-	ratingId, err := mapper.FromProtoToDbId(rating.Id)
-	if err != nil {
+	if err := s.refreshCategoryStats(ctx, queries, rating); err != nil {
 		return err
 	}
 
-	oldDbRating, err := queries.GetRatingById(ctx, ratingId)
+	scores, err := listRatingScores(ctx, queries, rating)
 	if err != nil {
 		return err
 	}
 
-	oldRating, err := mapper.FromDbRatingToResponse(oldDbRating, nil)
-	if err != nil {
+	if err := s.removeFromAggregateStats(ctx, queries, rating, scores); err != nil {
 		return err
 	}
 
-	updatedUpsertParams, err := mapper.UpdateUserStats(rating, oldRating, userStats)
-	if err != nil {
-		return err
-	}
-
-	_, err = queries.UpsertUserStats(ctx, updatedUpsertParams)
-	if err != nil {
+	if err := queries.RemoveFromGlobalStats(ctx, rating.Total); err != nil {
 		return err
 	}
 
-	return nil
+	return s.snapshotUserStats(ctx, queries, rating)
 }
 
-func (s *Service) removeFromUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+func (s *Service) addToUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
 	userId, err := mapper.FromProtoToDbId(rating.User.Id)
 	if err != nil {
 		return err
 	}
 
-	userStats, err := queries.GetStatsByUserId(ctx, userId)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil
-	}
-
-	updatedUpsertParams, err := mapper.RemoveFromUserStats(rating, userStats)
-	if err != nil {
+	// The row is created before it is locked, otherwise concurrent first
+	// ratings of the user would not wait for each other.
+	if err := queries.InsertEmptyUserStats(ctx, userId); err != nil {
 		return err
 	}
 
-	_, err = queries.UpsertUserStats(ctx, updatedUpsertParams)
+	userStats, err := queries.GetStatsByUserIdForUpdate(ctx, userId)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (s *Service) addToGlobalStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
-	globalStats, err := queries.GetGlobalStats(ctx)
-	if errors.Is(err, pgx.ErrNoRows) {
-		upsertParams := mapper.FromRatingToDbGlobalStats(rating)
-		_, err = queries.UpsertGlobalStats(ctx, upsertParams)
-		if err != nil {
-			return err
-		}
-
-		return nil
-	}
-
-	if err != nil {
-		return err
-	}
-
-	updatedUpsertParams, err := mapper.AddToGlobalStats(rating, globalStats)
+	upsertParams, err := mapper.AddToUserStats(rating, userStats)
 	if err != nil {
 		return err
 	}
 
-	_, err = queries.UpsertGlobalStats(ctx, updatedUpsertParams)
+	_, err = queries.UpsertUserStats(ctx, upsertParams)
 	if err != nil {
 		return err
 	}
@@ -217,46 +147,41 @@ func (s *Service) addToGlobalStats(ctx context.Context, queries *db.Queries, rat
 	return nil
 }
 
-func (s *Service) updateGlobalStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
-	globalStats, err := queries.GetGlobalStats(ctx)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil
-	}
-
+func (s *Service) updateUserStats(ctx context.Context, queries *db.Queries, oldRating *pb.RatingResponse, rating *pb.RatingResponse) error {
+	userId, err := mapper.FromProtoToDbId(rating.User.Id)
 	if err != nil {
 		return err
 	}
 
-	ratingId, err := mapper.FromProtoToDbId(rating.Id)
-	if err != nil {
-		return err
+	userStats, err := queries.GetStatsByUserIdForUpdate(ctx, userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
 	}
 
-	oldDbRating, err := queries.GetRatingById(ctx, ratingId)
 	if err != nil {
 		return err
 	}
 
-	oldRating, err := mapper.FromDbRatingToResponse(oldDbRating, nil)
+	updatedUpsertParams, err := mapper.UpdateUserStats(rating, oldRating, userStats)
 	if err != nil {
 		return err
 	}
 
-	updatedUpsertParams, err := mapper.UpdateGlobalStats(rating, oldRating, globalStats)
+	_, err = queries.UpsertUserStats(ctx, updatedUpsertParams)
 	if err != nil {
 		return err
 	}
 
-	_, err = queries.UpsertGlobalStats(ctx, updatedUpsertParams)
+	return nil
+}
+
+func (s *Service) removeFromUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+	userId, err := mapper.FromProtoToDbId(rating.User.Id)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (s *Service) removeFromGlobalStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
-	globalStats, err := queries.GetGlobalStats(ctx)
+	userStats, err := queries.GetStatsByUserIdForUpdate(ctx, userId)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil
 	}
@@ -265,12 +190,12 @@ func (s *Service) removeFromGlobalStats(ctx context.Context, queries *db.Queries
 		return err
 	}
 
-	updatedUpsertParams, err := mapper.RemoveFromGlobalStats(rating, globalStats)
+	updatedUpsertParams, err := mapper.RemoveFromUserStats(rating, userStats)
 	if err != nil {
 		return err
 	}
 
-	_, err = queries.UpsertGlobalStats(ctx, updatedUpsertParams)
+	_, err = queries.UpsertUserStats(ctx, updatedUpsertParams)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,22 @@
+package stat
+
+import (
+	"context"
+
+	pb "github.com/hyperremix/song-contest-rater-protos/v3"
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+)
+
+// snapshotUserStats records the current stats of the rating's author and
+// the global average as the author's stats of the day. Later rating writes
+// on the same day overwrite the snapshot, so every day keeps the stats of
+// its last rating write.
+func (s *Service) snapshotUserStats(ctx context.Context, queries *db.Queries, rating *pb.RatingResponse) error {
+	userId, err := mapper.FromProtoToDbId(rating.User.Id)
+	if err != nil {
+		return err
+	}
+
+	return queries.UpsertUserStatsSnapshots(ctx, userId)
+}
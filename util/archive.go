@@ -0,0 +1,33 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+)
+
+// ArchiveFile is a file of an archive, its data is encoded as JSON.
+type ArchiveFile struct {
+	Name string
+	Data any
+}
+
+// WriteJsonArchive writes the files as a zip archive in the given order.
+func WriteJsonArchive(w io.Writer, files []ArchiveFile) error {
+	archive := zip.NewWriter(w)
+
+	for _, file := range files {
+		writer, err := archive.Create(file.Name)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.Data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
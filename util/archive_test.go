@@ -0,0 +1,39 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJsonArchive(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := WriteJsonArchive(&buffer, []ArchiveFile{
+		{Name: "profile.json", Data: map[string]string{"firstname": "Käärijä"}},
+		{Name: "ratings.json", Data: []int{1, 2}},
+	})
+	assert.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, archive.File, 2)
+
+	contents := make([]string, len(archive.File))
+	for i, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		contents[i] = file.Name + ":" + string(data)
+	}
+
+	assert.Equal(t, []string{
+		"profile.json:{\n  \"firstname\": \"Käärijä\"\n}\n",
+		"ratings.json:[\n  1,\n  2\n]\n",
+	}, contents)
+}
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Category is a rating category of a competition. Scores have to lie
+// between Min and Max and count Weight times towards the total.
+type Category struct {
+	Name   string
+	Min    int32
+	Max    int32
+	Weight int32
+}
+
+const (
+	MaxCategoryScore  int32 = 100
+	MaxCategoryWeight int32 = 10
+	// MaxRatingTotal is the highest weighted sum the categories of a
+	// competition may add up to, so that averages of the totals fit the
+	// DECIMAL(6,2) columns of the stats.
+	MaxRatingTotal int32 = 9999
+)
+
+// DefaultCategories are the categories of a competition that has not
+// been configured otherwise.
+var DefaultCategories = []Category{
+	{Name: "song", Min: 1, Max: 15, Weight: 1},
+	{Name: "singing", Min: 1, Max: 15, Weight: 1},
+	{Name: "show", Min: 1, Max: 15, Weight: 1},
+	{Name: "looks", Min: 1, Max: 15, Weight: 1},
+	{Name: "clothes", Min: 1, Max: 15, Weight: 1},
+}
+
+func ValidateCategories(categories []Category) error {
+	if len(categories) == 0 {
+		return errors.New("at least one category is required")
+	}
+
+	var total int32
+	names := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if category.Name == "" {
+			return errors.New("category name must not be empty")
+		}
+
+		if names[category.Name] {
+			return fmt.Errorf("category %s is defined more than once", category.Name)
+		}
+		names[category.Name] = true
+
+		if category.Min < 0 {
+			return fmt.Errorf("min of category %s must not be negative", category.Name)
+		}
+
+		if category.Min > category.Max {
+			return fmt.Errorf("min of category %s must not be greater than max", category.Name)
+		}
+
+		if category.Max > MaxCategoryScore {
+			return fmt.Errorf("max of category %s must not be greater than %d", category.Name, MaxCategoryScore)
+		}
+
+		if category.Weight < 1 || category.Weight > MaxCategoryWeight {
+			return fmt.Errorf("weight of category %s must be between 1 and %d", category.Name, MaxCategoryWeight)
+		}
+
+		total += category.Max * category.Weight
+	}
+
+	if total > MaxRatingTotal {
+		return fmt.Errorf("the highest possible total of %d must not be greater than %d", total, MaxRatingTotal)
+	}
+
+	return nil
+}
+
+// ValidateScores checks that there is exactly one score within range
+// for every category.
+func ValidateScores(scores map[string]int32, categories []Category) error {
+	names := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		names[category.Name] = true
+
+		score, ok := scores[category.Name]
+		if !ok {
+			return fmt.Errorf("score for category %s is missing", category.Name)
+		}
+
+		if score < category.Min || score > category.Max {
+			return fmt.Errorf("score for category %s must be between %d and %d", category.Name, category.Min, category.Max)
+		}
+	}
+
+	for name := range scores {
+		if !names[name] {
+			return fmt.Errorf("category %s does not exist", name)
+		}
+	}
+
+	return nil
+}
+
+// WeightedSum returns the sum of the scores multiplied by the weight of
+// their category.
+func WeightedSum(scores map[string]int32, categories []Category) int32 {
+	var sum int32
+	for _, category := range categories {
+		sum += scores[category.Name] * category.Weight
+	}
+
+	return sum
+}
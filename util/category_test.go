@@ -0,0 +1,48 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var finalCategories = []Category{
+	{Name: "song", Min: 1, Max: 15, Weight: 2},
+	{Name: "singing", Min: 1, Max: 15, Weight: 1},
+	{Name: "staging", Min: 1, Max: 10, Weight: 1},
+}
+
+func TestValidateCategories(t *testing.T) {
+	assert.NoError(t, ValidateCategories(DefaultCategories))
+	assert.NoError(t, ValidateCategories(finalCategories))
+
+	assert.Error(t, ValidateCategories(nil))
+	assert.Error(t, ValidateCategories([]Category{{Name: "", Min: 1, Max: 15, Weight: 1}}))
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: 1, Max: 15, Weight: 1}, {Name: "song", Min: 1, Max: 10, Weight: 1}}))
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: 15, Max: 1, Weight: 1}}))
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: 1, Max: 15, Weight: 0}}))
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: -1, Max: 15, Weight: 1}}), "negative min")
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: 1, Max: 1000, Weight: 1}}), "max too high")
+	assert.Error(t, ValidateCategories([]Category{{Name: "song", Min: 1, Max: 15, Weight: 100}}), "weight too high")
+
+	tooMany := make([]Category, 0, 11)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"} {
+		tooMany = append(tooMany, Category{Name: name, Min: 0, Max: MaxCategoryScore, Weight: MaxCategoryWeight})
+	}
+	assert.NoError(t, ValidateCategories(tooMany[:9]))
+	assert.Error(t, ValidateCategories(tooMany), "total too high")
+}
+
+func TestValidateScores(t *testing.T) {
+	assert.NoError(t, ValidateScores(map[string]int32{"song": 12, "singing": 1, "staging": 10}, finalCategories))
+
+	assert.Error(t, ValidateScores(map[string]int32{"song": 12, "singing": 1}, finalCategories), "missing category")
+	assert.Error(t, ValidateScores(map[string]int32{"song": 12, "singing": 1, "staging": 11}, finalCategories), "score above max")
+	assert.Error(t, ValidateScores(map[string]int32{"song": 0, "singing": 1, "staging": 10}, finalCategories), "score below min")
+	assert.Error(t, ValidateScores(map[string]int32{"song": 12, "singing": 1, "staging": 10, "looks": 5}, finalCategories), "unknown category")
+}
+
+func TestWeightedSum(t *testing.T) {
+	assert.Equal(t, int32(2*12+1+10), WeightedSum(map[string]int32{"song": 12, "singing": 1, "staging": 10}, finalCategories))
+	assert.Equal(t, int32(15), WeightedSum(map[string]int32{"song": 3, "singing": 3, "show": 3, "looks": 3, "clothes": 3}, DefaultCategories))
+}
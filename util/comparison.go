@@ -0,0 +1,95 @@
+package util
+
+import (
+	"sort"
+)
+
+// RankComparison is the rank of an act by the community ratings and by the
+// official results.
+type RankComparison struct {
+	Id            string
+	CommunityRank int32
+	OfficialRank  int32
+}
+
+// Difference is positive if the community ranked the act higher than the
+// official results and negative if it ranked the act lower.
+func (c RankComparison) Difference() int32 {
+	return c.OfficialRank - c.CommunityRank
+}
+
+// Comparison holds the rank correlations between the community ranking and
+// the official results and the acts ordered by their official rank.
+type Comparison struct {
+	KendallTau float64
+	Spearman   float64
+	Acts       []RankComparison
+}
+
+// CompareRankings compares the community ranking derived from the rating
+// averages with the official placements. Only acts with both are ranked,
+// acts with the same average share the best of their ranks. The
+// correlations account for such ties: Spearman correlates the average
+// ranks of tied acts and Kendall's tau is the tau-b. The comparison is
+// undefined for fewer than two such acts.
+func CompareRankings(ratingAvgs map[string]float64, placements map[string]int32) (Comparison, bool) {
+	ids := make([]string, 0, len(placements))
+	for id := range placements {
+		if _, ok := ratingAvgs[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < 2 {
+		return Comparison{}, false
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if ratingAvgs[ids[i]] != ratingAvgs[ids[j]] {
+			return ratingAvgs[ids[i]] > ratingAvgs[ids[j]]
+		}
+
+		return ids[i] < ids[j]
+	})
+
+	communityRanks := make(map[string]int32, len(ids))
+	averageRanks := make(map[string]float64, len(ids))
+	for start := 0; start < len(ids); {
+		end := start + 1
+		for end < len(ids) && ratingAvgs[ids[end]] == ratingAvgs[ids[start]] {
+			end++
+		}
+
+		// The acts from start to end share the ranks start+1 to end.
+		for _, id := range ids[start:end] {
+			communityRanks[id] = int32(start + 1)
+			averageRanks[id] = float64(start+1+end) / 2
+		}
+
+		start = end
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return placements[ids[i]] < placements[ids[j]]
+	})
+
+	comparison := Comparison{Acts: make([]RankComparison, len(ids))}
+	pairs := make([]RankedPair, len(ids))
+	communityRanksFloat := make([]float64, len(ids))
+	officialRanksFloat := make([]float64, len(ids))
+	for i, id := range ids {
+		comparison.Acts[i] = RankComparison{
+			Id:            id,
+			CommunityRank: communityRanks[id],
+			OfficialRank:  int32(i + 1),
+		}
+		pairs[i] = RankedPair{Predicted: communityRanks[id], Actual: int32(i + 1)}
+		communityRanksFloat[i] = averageRanks[id]
+		officialRanksFloat[i] = float64(i + 1)
+	}
+
+	comparison.KendallTau, _ = KendallTau(pairs)
+	comparison.Spearman, _ = PearsonCorrelation(communityRanksFloat, officialRanksFloat)
+
+	return comparison, true
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRankings(t *testing.T) {
+	ratingAvgs := map[string]float64{"a": 60, "b": 50, "c": 40, "unplaced": 70}
+	placements := map[string]int32{"a": 3, "b": 5, "c": 1, "unrated": 2}
+
+	comparison, ok := CompareRankings(ratingAvgs, placements)
+
+	assert.True(t, ok)
+	assert.Equal(t, []RankComparison{
+		{Id: "c", CommunityRank: 3, OfficialRank: 1},
+		{Id: "a", CommunityRank: 1, OfficialRank: 2},
+		{Id: "b", CommunityRank: 2, OfficialRank: 3},
+	}, comparison.Acts)
+	assert.InDelta(t, -1.0/3.0, comparison.KendallTau, 1e-9)
+	assert.InDelta(t, -0.5, comparison.Spearman, 1e-9)
+	assert.Equal(t, int32(-2), comparison.Acts[0].Difference(), "the community ranked the winner lower")
+}
+
+func TestCompareRankingsWithTies(t *testing.T) {
+	comparison, ok := CompareRankings(map[string]float64{"a": 50, "b": 50, "c": 40}, map[string]int32{"a": 1, "b": 2, "c": 3})
+
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), comparison.Acts[0].CommunityRank)
+	assert.Equal(t, int32(1), comparison.Acts[1].CommunityRank)
+	assert.Equal(t, int32(3), comparison.Acts[2].CommunityRank)
+	assert.InDelta(t, math.Sqrt(2.0/3.0), comparison.KendallTau, 1e-9, "tau-b corrects for the tie")
+	assert.InDelta(t, math.Sqrt(3.0)/2, comparison.Spearman, 1e-9, "tied acts share the average rank 1.5")
+}
+
+func TestCompareRankingsWithOnlyTies(t *testing.T) {
+	comparison, ok := CompareRankings(map[string]float64{"a": 50, "b": 50}, map[string]int32{"a": 1, "b": 2})
+
+	assert.True(t, ok)
+	assert.Zero(t, comparison.KendallTau, "the correlation is undefined without community order")
+	assert.Zero(t, comparison.Spearman)
+}
+
+func TestCompareRankingsWithoutOverlap(t *testing.T) {
+	_, ok := CompareRankings(map[string]float64{"a": 50}, map[string]int32{"a": 1, "b": 2})
+
+	assert.False(t, ok)
+}
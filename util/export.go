@@ -0,0 +1,155 @@
+package util
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	ExportFormatCsv    = "csv"
+	ExportFormatJson   = "json"
+	ExportFormatNdjson = "ndjson"
+)
+
+// utf8Bom makes spreadsheet applications read a CSV file as UTF-8 instead
+// of guessing the encoding, which garbles the names of most artists.
+const utf8Bom = "\ufeff"
+
+// ExportRow is a single row of an export. It is encoded as a JSON object
+// for the JSON formats and as its CSV record for the CSV format.
+type ExportRow interface {
+	CsvRecord() []string
+}
+
+// ExportWriter encodes rows one by one so that an export is never held in
+// memory as a whole. Close has to be called after the last row to
+// complete the output.
+type ExportWriter interface {
+	Write(row ExportRow) error
+	Close() error
+}
+
+// NewExportWriter returns a writer for the format. Nothing is written
+// before the first row or Close, so that a response can still set its
+// headers after the format was validated. The header is only written by
+// the CSV format, the JSON formats use the field names of the rows.
+func NewExportWriter(w io.Writer, format string, header []string) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCsv:
+		return &csvExportWriter{output: w, writer: csv.NewWriter(w), header: header}, nil
+	case ExportFormatJson:
+		return &jsonExportWriter{writer: w}, nil
+	case ExportFormatNdjson:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("format must be %s, %s or %s", ExportFormatCsv, ExportFormatJson, ExportFormatNdjson)
+	}
+}
+
+// ExportContentType returns the media type of the format.
+func ExportContentType(format string) string {
+	switch format {
+	case ExportFormatCsv:
+		return "text/csv; charset=utf-8"
+	case ExportFormatNdjson:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+type csvExportWriter struct {
+	output  io.Writer
+	writer  *csv.Writer
+	header  []string
+	started bool
+}
+
+// start writes the byte order mark and the header before the first row.
+func (w *csvExportWriter) start() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+	if _, err := io.WriteString(w.output, utf8Bom); err != nil {
+		return err
+	}
+
+	return w.writer.Write(w.header)
+}
+
+func (w *csvExportWriter) Write(row ExportRow) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	if err := w.writer.Write(row.CsvRecord()); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvExportWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonExportWriter writes the rows as the elements of a JSON array.
+type jsonExportWriter struct {
+	writer io.Writer
+	rows   int
+}
+
+func (w *jsonExportWriter) Write(row ExportRow) error {
+	separator := ","
+	if w.rows == 0 {
+		separator = "["
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w.writer, separator); err != nil {
+		return err
+	}
+
+	if _, err := w.writer.Write(data); err != nil {
+		return err
+	}
+
+	w.rows++
+	return nil
+}
+
+func (w *jsonExportWriter) Close() error {
+	end := "]\n"
+	if w.rows == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(w.writer, end)
+	return err
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonExportWriter) Write(row ExportRow) error {
+	return w.encoder.Encode(row)
+}
+
+func (w *ndjsonExportWriter) Close() error {
+	return nil
+}
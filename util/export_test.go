@@ -0,0 +1,66 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testExportRow struct {
+	Name  string `json:"name"`
+	Total string `json:"total"`
+}
+
+func (r testExportRow) CsvRecord() []string {
+	return []string{r.Name, r.Total}
+}
+
+var testExportRows = []testExportRow{
+	{Name: "Käärijä", Total: "42"},
+	{Name: "Loreen, Tattoo", Total: "40"},
+}
+
+func writeTestExport(t *testing.T, format string, rows []testExportRow) string {
+	var buffer bytes.Buffer
+
+	writer, err := NewExportWriter(&buffer, format, []string{"name", "total"})
+	assert.NoError(t, err)
+
+	for _, row := range rows {
+		assert.NoError(t, writer.Write(row))
+	}
+	assert.NoError(t, writer.Close())
+
+	return buffer.String()
+}
+
+func TestExportWriterCsv(t *testing.T) {
+	assert.Equal(t, "\ufeffname,total\nKäärijä,42\n\"Loreen, Tattoo\",40\n", writeTestExport(t, ExportFormatCsv, testExportRows))
+	assert.Equal(t, "\ufeffname,total\n", writeTestExport(t, ExportFormatCsv, nil))
+}
+
+func TestExportWriterJson(t *testing.T) {
+	assert.Equal(t, `[{"name":"Käärijä","total":"42"},{"name":"Loreen, Tattoo","total":"40"}]`+"\n", writeTestExport(t, ExportFormatJson, testExportRows))
+	assert.Equal(t, "[]\n", writeTestExport(t, ExportFormatJson, nil))
+}
+
+func TestExportWriterNdjson(t *testing.T) {
+	assert.Equal(t, `{"name":"Käärijä","total":"42"}`+"\n"+`{"name":"Loreen, Tattoo","total":"40"}`+"\n", writeTestExport(t, ExportFormatNdjson, testExportRows))
+	assert.Equal(t, "", writeTestExport(t, ExportFormatNdjson, nil))
+}
+
+func TestNewExportWriterWritesNothingBeforeTheFirstRow(t *testing.T) {
+	for _, format := range []string{ExportFormatCsv, ExportFormatJson, ExportFormatNdjson} {
+		var buffer bytes.Buffer
+
+		_, err := NewExportWriter(&buffer, format, []string{"name", "total"})
+		assert.NoError(t, err)
+		assert.Empty(t, buffer.String(), format)
+	}
+}
+
+func TestNewExportWriterUnknownFormat(t *testing.T) {
+	_, err := NewExportWriter(&bytes.Buffer{}, "xlsx", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const (
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 8
+)
+
+// NewInviteCode returns a random, human friendly code without
+// easily confused characters like 0/O and 1/I.
+func NewInviteCode() (string, error) {
+	code := make([]byte, inviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultPageLimit int32 = 50
+	MaxPageLimit     int32 = 200
+)
+
+// Page is a window of a sorted list. Clients receive the position of the
+// next page as an opaque cursor so that the encoding can change without
+// breaking them.
+type Page struct {
+	Limit int32
+	// After is the position of the last row of the previous page, it is
+	// nil on the first page.
+	After  *Cursor
+	SortBy string
+	Desc   bool
+}
+
+// Cursor is the position of a row in a sorted list: the value of the sort
+// field of the row and its id, which breaks ties between equal values.
+// Pages start after the position instead of skipping a number of rows, so
+// rows that are written between two pages are neither skipped nor listed
+// twice.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    string `json:"id"`
+}
+
+// NewPage validates the pagination parameters of a list request. The sort
+// is given as a field name which is prefixed with "-" to sort descending
+// and has to be one of sortFields, defaultSort is used if it is empty.
+// Lists are always paginated, DefaultPageLimit is used if no limit is
+// given.
+func NewPage(limit int32, cursor string, sort string, defaultSort string, sortFields ...string) (Page, error) {
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+
+	if limit < 0 || limit > MaxPageLimit {
+		return Page{}, fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	if sort == "" {
+		sort = defaultSort
+	}
+
+	sortBy := strings.TrimPrefix(sort, "-")
+	if !slices.Contains(sortFields, sortBy) {
+		return Page{}, fmt.Errorf("sort must be one of %s", strings.Join(sortFields, ", "))
+	}
+
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if after != nil && after.Sort != sort {
+		return Page{}, errors.New("cursor belongs to another sort")
+	}
+
+	return Page{
+		Limit:  limit,
+		After:  after,
+		SortBy: sortBy,
+		Desc:   strings.HasPrefix(sort, "-"),
+	}, nil
+}
+
+// Sort returns the sort of the page the way it is given in requests.
+func (p Page) Sort() string {
+	if p.Desc {
+		return "-" + p.SortBy
+	}
+
+	return p.SortBy
+}
+
+// FetchLimit is the number of rows to query for the page. The extra row
+// tells whether there is a next page.
+func (p Page) FetchLimit() int32 {
+	return p.Limit + 1
+}
+
+// Paginate trims rows that were queried with the fetch limit of the page
+// to the page and returns the cursor of the next page, which is empty on
+// the last page. position returns the value of the sort field and the id
+// of a row.
+func Paginate[T any](p Page, rows []T, position func(T) (string, string, error)) ([]T, string, error) {
+	if int32(len(rows)) <= p.Limit {
+		return rows, "", nil
+	}
+
+	rows = rows[:p.Limit]
+
+	value, id, err := position(rows[len(rows)-1])
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, err := encodeCursor(Cursor{Sort: p.Sort(), Value: value, Id: id})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, cursor, nil
+}
+
+func encodeCursor(cursor Cursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (*Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var after Cursor
+	if err := json.Unmarshal(decoded, &after); err != nil || after.Id == "" {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &after, nil
+}
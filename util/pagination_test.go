@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPage(t *testing.T) {
+	page, err := NewPage(0, "", "", "-createdAt", "createdAt", "total")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Limit: DefaultPageLimit, SortBy: "createdAt", Desc: true}, page, "lists without limit are paginated by default")
+	assert.Equal(t, DefaultPageLimit+1, page.FetchLimit())
+
+	page, err = NewPage(10, "", "total", "-createdAt", "createdAt", "total")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Limit: 10, SortBy: "total"}, page)
+	assert.Equal(t, int32(11), page.FetchLimit())
+
+	_, err = NewPage(MaxPageLimit+1, "", "", "total", "total")
+	assert.Error(t, err)
+
+	_, err = NewPage(-1, "", "", "total", "total")
+	assert.Error(t, err)
+
+	_, err = NewPage(10, "", "-userId", "total", "total")
+	assert.Error(t, err, "sorting by a field that is not allowed")
+
+	_, err = NewPage(10, "not a cursor", "", "total", "total")
+	assert.Error(t, err)
+}
+
+type row struct {
+	id    string
+	total int
+}
+
+func rowPosition(r row) (string, string, error) {
+	return fmt.Sprint(r.total), r.id, nil
+}
+
+func TestPaginate(t *testing.T) {
+	page, _ := NewPage(2, "", "-total", "total", "total")
+
+	rows, cursor, err := Paginate(page, []row{{"a", 30}, {"b", 20}, {"c", 10}}, rowPosition)
+	assert.NoError(t, err)
+	assert.Equal(t, []row{{"a", 30}, {"b", 20}}, rows)
+	assert.NotEmpty(t, cursor)
+
+	nextPage, err := NewPage(0, cursor, "-total", "total", "total")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPageLimit, nextPage.Limit, "pages after a cursor are limited")
+	assert.Equal(t, &Cursor{Sort: "-total", Value: "20", Id: "b"}, nextPage.After)
+
+	rows, cursor, err = Paginate(nextPage, []row{{"c", 10}}, rowPosition)
+	assert.NoError(t, err)
+	assert.Equal(t, []row{{"c", 10}}, rows)
+	assert.Empty(t, cursor, "the last page has no next cursor")
+}
+
+func TestPaginateCursorBelongsToSort(t *testing.T) {
+	page, _ := NewPage(1, "", "total", "total", "total", "createdAt")
+
+	_, cursor, err := Paginate(page, []row{{"a", 10}, {"b", 20}}, rowPosition)
+	assert.NoError(t, err)
+
+	_, err = NewPage(1, cursor, "-total", "total", "total", "createdAt")
+	assert.Error(t, err, "the cursor of an ascending sort cannot continue a descending one")
+
+	_, err = NewPage(1, cursor, "createdAt", "total", "total", "createdAt")
+	assert.Error(t, err)
+}
+
+func TestPaginateWithoutLimit(t *testing.T) {
+	page, _ := NewPage(0, "", "", "total", "total")
+
+	rows := make([]row, DefaultPageLimit+1)
+	for i := range rows {
+		rows[i] = row{fmt.Sprint(i), i}
+	}
+
+	rows, cursor, err := Paginate(page, rows, rowPosition)
+	assert.NoError(t, err)
+	assert.Len(t, rows, int(DefaultPageLimit))
+	assert.NotEmpty(t, cursor)
+}
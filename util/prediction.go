@@ -0,0 +1,139 @@
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	PredictionMetricKendallTau       = "kendallTau"
+	PredictionMetricPositionDistance = "positionDistance"
+)
+
+// RankedPair is the predicted and the actual rank of an act.
+type RankedPair struct {
+	Predicted int32
+	Actual    int32
+}
+
+// PredictionScore is the accuracy of the ranking predictions of a user
+// averaged over the competitions that have results. PositionDistance is
+// the average number of places a predicted act was off by.
+type PredictionScore struct {
+	UserId           string
+	Competitions     int32
+	KendallTau       float64
+	PositionDistance float64
+}
+
+// ScorePredictions scores the ranking predictions keyed by user and
+// competition. Competitions with fewer than two ranked acts cannot be
+// scored and are left out. The result is ordered from the best to the
+// worst user by metric.
+func ScorePredictions(predictions map[string]map[string][]RankedPair, metric string) []PredictionScore {
+	scores := make([]PredictionScore, 0, len(predictions))
+	for userId, competitions := range predictions {
+		score := PredictionScore{UserId: userId}
+
+		var tauSum float64
+		var distanceSum, actCount int32
+		for _, pairs := range competitions {
+			tau, ok := KendallTau(pairs)
+			if !ok {
+				continue
+			}
+
+			score.Competitions++
+			tauSum += tau
+			distanceSum += PositionDistance(pairs)
+			actCount += int32(len(pairs))
+		}
+
+		if score.Competitions == 0 {
+			continue
+		}
+
+		score.KendallTau = tauSum / float64(score.Competitions)
+		score.PositionDistance = float64(distanceSum) / float64(actCount)
+		scores = append(scores, score)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if metric == PredictionMetricPositionDistance && a.PositionDistance != b.PositionDistance {
+			return a.PositionDistance < b.PositionDistance
+		}
+
+		if a.KendallTau != b.KendallTau {
+			return a.KendallTau > b.KendallTau
+		}
+
+		if a.Competitions != b.Competitions {
+			return a.Competitions > b.Competitions
+		}
+
+		return a.UserId < b.UserId
+	})
+
+	return scores
+}
+
+// KendallTau returns the rank correlation between the predicted and the
+// actual ranks, from 1 for the same order to -1 for the reverse order.
+// Ties are corrected for (tau-b), so rankings with shared ranks can still
+// reach 1. It is undefined for fewer than two pairs or if either side
+// ranks all acts the same.
+func KendallTau(pairs []RankedPair) (float64, bool) {
+	n := len(pairs)
+	if n < 2 {
+		return 0, false
+	}
+
+	var concordant, discordant, predictedUntied, actualUntied int
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			predicted := pairs[i].Predicted - pairs[j].Predicted
+			actual := pairs[i].Actual - pairs[j].Actual
+			if predicted != 0 {
+				predictedUntied++
+			}
+			if actual != 0 {
+				actualUntied++
+			}
+
+			switch product := predicted * actual; {
+			case product > 0:
+				concordant++
+			case product < 0:
+				discordant++
+			}
+		}
+	}
+
+	if predictedUntied == 0 || actualUntied == 0 {
+		return 0, false
+	}
+
+	return float64(concordant-discordant) / math.Sqrt(float64(predictedUntied)*float64(actualUntied)), true
+}
+
+// PositionDistance returns the sum of the absolute differences between the
+// predicted and the actual ranks.
+func PositionDistance(pairs []RankedPair) int32 {
+	var distance int32
+	for _, pair := range pairs {
+		if pair.Predicted > pair.Actual {
+			distance += pair.Predicted - pair.Actual
+		} else {
+			distance += pair.Actual - pair.Predicted
+		}
+	}
+
+	return distance
+}
+
+// ValidatePlacements checks that the official placements are positive and
+// that no placement is given twice.
+func ValidatePlacements(placements []int32) error {
+	return validateRanks(placements, "placement")
+}
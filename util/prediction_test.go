@@ -0,0 +1,64 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKendallTau(t *testing.T) {
+	tau, ok := KendallTau([]RankedPair{{1, 1}, {2, 2}, {3, 3}})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, tau)
+
+	tau, ok = KendallTau([]RankedPair{{1, 3}, {2, 2}, {3, 1}})
+	assert.True(t, ok)
+	assert.Equal(t, -1.0, tau)
+
+	tau, ok = KendallTau([]RankedPair{{1, 2}, {2, 1}, {3, 3}})
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0/3.0, tau, 1e-9)
+
+	tau, ok = KendallTau([]RankedPair{{1, 1}, {1, 2}, {3, 3}, {3, 4}})
+	assert.True(t, ok)
+	assert.InDelta(t, math.Sqrt(2.0/3.0), tau, 1e-9, "tied ranks do not count against the order")
+
+	_, ok = KendallTau([]RankedPair{{1, 1}})
+	assert.False(t, ok, "a single act has no order")
+
+	_, ok = KendallTau([]RankedPair{{1, 1}, {1, 2}})
+	assert.False(t, ok, "a ranking with all acts tied has no order")
+}
+
+func TestPositionDistance(t *testing.T) {
+	assert.Equal(t, int32(0), PositionDistance([]RankedPair{{1, 1}, {2, 2}}))
+	assert.Equal(t, int32(4), PositionDistance([]RankedPair{{1, 3}, {2, 2}, {3, 1}}))
+}
+
+func TestScorePredictions(t *testing.T) {
+	predictions := map[string]map[string][]RankedPair{
+		"perfect": {"final": {{1, 1}, {2, 2}, {3, 3}}},
+		"close":   {"final": {{1, 2}, {2, 1}, {3, 3}}},
+		"reverse": {"final": {{1, 3}, {2, 2}, {3, 1}}},
+		"single":  {"final": {{1, 1}}},
+	}
+
+	scores := ScorePredictions(predictions, PredictionMetricKendallTau)
+
+	assert.Len(t, scores, 3)
+	assert.Equal(t, "perfect", scores[0].UserId)
+	assert.Equal(t, int32(1), scores[0].Competitions)
+	assert.Equal(t, "close", scores[1].UserId)
+	assert.InDelta(t, 2.0/3.0, scores[1].PositionDistance, 1e-9)
+	assert.Equal(t, "reverse", scores[2].UserId)
+
+	scores = ScorePredictions(predictions, PredictionMetricPositionDistance)
+	assert.Equal(t, []string{"perfect", "close", "reverse"}, []string{scores[0].UserId, scores[1].UserId, scores[2].UserId})
+}
+
+func TestValidatePlacements(t *testing.T) {
+	assert.NoError(t, ValidatePlacements([]int32{2, 1, 3}))
+	assert.Error(t, ValidatePlacements([]int32{1, 1}))
+	assert.Error(t, ValidatePlacements([]int32{0, 1}))
+}
@@ -0,0 +1,24 @@
+package util
+
+// QualificationAccuracy returns the share of correctly predicted
+// qualifiers. Predicting more acts than qualified counts the same as
+// missing qualifiers so that predicting every act does not pay off.
+func QualificationAccuracy(correct int32, predicted int32, qualified int32) float64 {
+	total := max(predicted, qualified)
+	if total == 0 {
+		return 0
+	}
+
+	return float64(correct) / float64(total)
+}
+
+// QualifiersComplete reports whether all qualifiers of a competition have
+// been marked, either because the expected number of qualifiers has been
+// reached or because they have been marked as final.
+func QualifiersComplete(qualifierCount int32, expectedCount *int32, isFinal bool) bool {
+	if isFinal {
+		return true
+	}
+
+	return expectedCount != nil && qualifierCount >= *expectedCount
+}
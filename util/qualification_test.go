@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualificationAccuracy(t *testing.T) {
+	assert.Equal(t, 1.0, QualificationAccuracy(10, 10, 10))
+	assert.Equal(t, 0.7, QualificationAccuracy(7, 10, 10))
+	assert.Equal(t, 0.5, QualificationAccuracy(10, 20, 10), "predicting too many acts")
+	assert.Equal(t, 0.5, QualificationAccuracy(5, 5, 10), "predicting too few acts")
+	assert.Equal(t, 0.0, QualificationAccuracy(0, 0, 0))
+}
+
+func TestQualifiersComplete(t *testing.T) {
+	expectedCount := int32(10)
+
+	assert.True(t, QualifiersComplete(10, &expectedCount, false), "expected count reached")
+	assert.False(t, QualifiersComplete(1, &expectedCount, false), "first qualifier marked")
+	assert.True(t, QualifiersComplete(9, &expectedCount, true), "marked as final")
+	assert.False(t, QualifiersComplete(10, nil, false), "no expected count")
+}
@@ -2,8 +2,10 @@ package util
 
 import pb "github.com/hyperremix/song-contest-rater-protos/v3"
 
+// RatingSum returns the weighted sum of the rating's category scores,
+// which is computed with WeightedSum when the rating is written.
 func RatingSum(rating *pb.RatingResponse) int32 {
-	return rating.Song + rating.Singing + rating.Show + rating.Looks + rating.Clothes
+	return rating.Total
 }
 
 func ManyRatingsSum(ratings []*pb.RatingResponse) int32 {
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"fmt"
+)
+
+// ValidateRunningOrder checks that the orders of the acts of a competition
+// are positive and that no order is given twice.
+func ValidateRunningOrder(orders []int32) error {
+	return validateRanks(orders, "order")
+}
+
+func validateRanks(ranks []int32, name string) error {
+	seen := make(map[int32]bool, len(ranks))
+	for _, rank := range ranks {
+		if rank < 1 {
+			return fmt.Errorf("%s must be at least 1", name)
+		}
+
+		if seen[rank] {
+			return fmt.Errorf("%s %d is given more than once", name, rank)
+		}
+		seen[rank] = true
+	}
+
+	return nil
+}
+
+// RunningOrderIssues are the positions missing from a running order that
+// starts at 1 and the positions given to more than one act.
+type RunningOrderIssues struct {
+	Gaps       []int32
+	Duplicates []int32
+}
+
+// FindRunningOrderIssues returns the gaps and duplicates of the orders in
+// ascending order.
+func FindRunningOrderIssues(orders []int32) RunningOrderIssues {
+	counts := make(map[int32]int, len(orders))
+	var last int32
+	for _, order := range orders {
+		counts[order]++
+		last = max(last, order)
+	}
+
+	issues := RunningOrderIssues{Gaps: make([]int32, 0), Duplicates: make([]int32, 0)}
+	for order := int32(1); order <= last; order++ {
+		switch {
+		case counts[order] == 0:
+			issues.Gaps = append(issues.Gaps, order)
+		case counts[order] > 1:
+			issues.Duplicates = append(issues.Duplicates, order)
+		}
+	}
+
+	return issues
+}
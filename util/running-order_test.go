@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRunningOrder(t *testing.T) {
+	assert.NoError(t, ValidateRunningOrder([]int32{2, 1, 3}))
+	assert.Error(t, ValidateRunningOrder([]int32{1, 2, 2}))
+	assert.Error(t, ValidateRunningOrder([]int32{0, 1}))
+}
+
+func TestFindRunningOrderIssues(t *testing.T) {
+	issues := FindRunningOrderIssues([]int32{1, 2, 3})
+	assert.Empty(t, issues.Gaps)
+	assert.Empty(t, issues.Duplicates)
+
+	issues = FindRunningOrderIssues([]int32{5, 2, 2, 1, 5})
+	assert.Equal(t, []int32{3, 4}, issues.Gaps)
+	assert.Equal(t, []int32{2, 5}, issues.Duplicates)
+}
@@ -0,0 +1,126 @@
+package util
+
+import "sort"
+
+// BallotPoints are the points awarded to the top ten acts of every ballot,
+// the same way the Eurovision Song Contest allocates them.
+var BallotPoints = []int32{12, 10, 8, 7, 6, 5, 4, 3, 2, 1}
+
+type ScoreboardEntry struct {
+	ActId string
+	Order int32
+	// Points is the sum of all points awarded by all ballots.
+	Points int32
+	// Voters is the number of ballots that awarded any points.
+	Voters int32
+	// PointsCount counts how often each value of BallotPoints was awarded.
+	PointsCount map[int32]int32
+}
+
+// BallotRating is a rating on the ballot of a single user.
+type BallotRating struct {
+	ActId string
+	Total int32
+	// Scores are the category scores in the category order of the
+	// competition.
+	Scores []int32
+}
+
+// AllocateBallotPoints ranks the ratings of a single user and returns
+// the points awarded per act id. Ratings with an equal total are ranked
+// by the scores of the competition's categories in category order before
+// falling back to the act id so that the ballot is always deterministic.
+func AllocateBallotPoints(ballot []BallotRating) map[string]int32 {
+	ranked := make([]BallotRating, len(ballot))
+	copy(ranked, ballot)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+
+		for k := 0; k < len(a.Scores) && k < len(b.Scores); k++ {
+			if a.Scores[k] != b.Scores[k] {
+				return a.Scores[k] > b.Scores[k]
+			}
+		}
+
+		return a.ActId < b.ActId
+	})
+
+	points := make(map[string]int32)
+	for i, rating := range ranked {
+		if i >= len(BallotPoints) {
+			break
+		}
+		points[rating.ActId] = BallotPoints[i]
+	}
+
+	return points
+}
+
+// Scoreboard adds up the points of all ballots for the acts of the given
+// running order, ignoring ratings of any other act, and returns the entries ranked by:
+//  1. total points
+//  2. number of ballots that awarded points to the act
+//  3. number of 12 points, then 10 points and so on
+//  4. earlier position in the running order
+func Scoreboard(runningOrder map[string]int32, ballots [][]BallotRating) []*ScoreboardEntry {
+	entries := make(map[string]*ScoreboardEntry, len(runningOrder))
+	for actId, order := range runningOrder {
+		entries[actId] = &ScoreboardEntry{
+			ActId:       actId,
+			Order:       order,
+			PointsCount: make(map[int32]int32),
+		}
+	}
+
+	for _, ballot := range ballots {
+		// Ratings of acts that left the running order must not take the
+		// points of the acts that are still in it.
+		current := make([]BallotRating, 0, len(ballot))
+		for _, rating := range ballot {
+			if _, ok := entries[rating.ActId]; ok {
+				current = append(current, rating)
+			}
+		}
+
+		for actId, points := range AllocateBallotPoints(current) {
+			entry := entries[actId]
+			entry.Points += points
+			entry.Voters++
+			entry.PointsCount[points]++
+		}
+	}
+
+	scoreboard := make([]*ScoreboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		scoreboard = append(scoreboard, entry)
+	}
+
+	sort.Slice(scoreboard, func(i, j int) bool {
+		a, b := scoreboard[i], scoreboard[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+
+		if a.Voters != b.Voters {
+			return a.Voters > b.Voters
+		}
+
+		for _, points := range BallotPoints {
+			if a.PointsCount[points] != b.PointsCount[points] {
+				return a.PointsCount[points] > b.PointsCount[points]
+			}
+		}
+
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+
+		return a.ActId < b.ActId
+	})
+
+	return scoreboard
+}
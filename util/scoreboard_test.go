@@ -0,0 +1,135 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rating(actId string, total int32) BallotRating {
+	return BallotRating{ActId: actId, Total: total, Scores: []int32{total}}
+}
+
+func TestAllocateBallotPoints(t *testing.T) {
+	var ballot []BallotRating
+	for i := int32(1); i <= 12; i++ {
+		ballot = append(ballot, rating(fmt.Sprintf("act-%02d", i), i))
+	}
+
+	points := AllocateBallotPoints(ballot)
+
+	assert.Len(t, points, 10)
+	assert.Equal(t, int32(12), points["act-12"])
+	assert.Equal(t, int32(10), points["act-11"])
+	assert.Equal(t, int32(8), points["act-10"])
+	assert.Equal(t, int32(1), points["act-03"])
+	assert.NotContains(t, points, "act-02")
+	assert.NotContains(t, points, "act-01")
+}
+
+func TestAllocateBallotPointsBreaksTiesByCategory(t *testing.T) {
+	ballot := []BallotRating{
+		{ActId: "a", Total: 15, Scores: []int32{5, 10}},
+		{ActId: "b", Total: 15, Scores: []int32{10, 5}},
+		{ActId: "c", Total: 15, Scores: []int32{5, 10}},
+	}
+
+	points := AllocateBallotPoints(ballot)
+
+	assert.Equal(t, int32(12), points["b"])
+	assert.Equal(t, int32(10), points["a"])
+	assert.Equal(t, int32(8), points["c"])
+}
+
+func TestScoreboard(t *testing.T) {
+	tests := []struct {
+		name         string
+		runningOrder map[string]int32
+		ballots      [][]BallotRating
+		expected     []string
+	}{
+		{
+			name:         "Ranked by points",
+			runningOrder: map[string]int32{"a": 1, "b": 2, "c": 3},
+			ballots: [][]BallotRating{
+				{rating("a", 10), rating("b", 20), rating("c", 30)},
+				{rating("a", 10), rating("b", 30), rating("c", 20)},
+			},
+			expected: []string{"b", "c", "a"},
+		},
+		{
+			name:         "Tie broken by number of voters",
+			runningOrder: map[string]int32{"a": 1, "b": 2},
+			ballots: [][]BallotRating{
+				{rating("a", 30), rating("b", 20)},
+				{rating("b", 30)},
+			},
+			expected: []string{"b", "a"},
+		},
+		{
+			name:         "Tie broken by number of twelve points",
+			runningOrder: map[string]int32{"a": 1, "b": 2, "c": 3},
+			ballots: [][]BallotRating{
+				{rating("a", 30), rating("b", 10), rating("c", 20)},
+				{rating("b", 30), rating("a", 10), rating("c", 20)},
+				{rating("b", 30), rating("c", 20), rating("a", 10)},
+				{rating("a", 30), rating("c", 20), rating("b", 10)},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:         "Tie broken by running order",
+			runningOrder: map[string]int32{"a": 2, "b": 1},
+			ballots: [][]BallotRating{
+				{rating("a", 30), rating("b", 20)},
+				{rating("b", 30), rating("a", 20)},
+			},
+			expected: []string{"b", "a"},
+		},
+		{
+			name:         "Acts without ratings are included",
+			runningOrder: map[string]int32{"a": 1, "b": 2},
+			ballots: [][]BallotRating{
+				{rating("b", 30)},
+			},
+			expected: []string{"b", "a"},
+		},
+		{
+			name:         "Acts outside the running order are ignored",
+			runningOrder: map[string]int32{"a": 1, "b": 2},
+			ballots: [][]BallotRating{
+				{rating("a", 20), rating("removed", 40)},
+				{rating("b", 30), rating("a", 20)},
+			},
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoreboard := Scoreboard(tt.runningOrder, tt.ballots)
+
+			actIds := make([]string, len(scoreboard))
+			for i, entry := range scoreboard {
+				actIds[i] = entry.ActId
+			}
+
+			assert.Equal(t, tt.expected, actIds)
+		})
+	}
+}
+
+func TestScoreboardIgnoresActsOutsideRunningOrder(t *testing.T) {
+	ballots := [][]BallotRating{
+		{rating("b", 30), rating("removed", 40)},
+	}
+
+	scoreboard := Scoreboard(map[string]int32{"a": 1, "b": 2}, ballots)
+
+	assert.Len(t, scoreboard, 2)
+	assert.Equal(t, "b", scoreboard[0].ActId)
+	assert.Equal(t, int32(12), scoreboard[0].Points)
+	assert.Equal(t, int32(1), scoreboard[0].PointsCount[12])
+	assert.Equal(t, int32(0), scoreboard[1].Points)
+}
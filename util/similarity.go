@@ -0,0 +1,96 @@
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+// Similarity is the Pearson correlation between the ratings of a user and
+// the ratings of another user over the acts both of them rated.
+type Similarity struct {
+	UserId      string
+	Correlation float64
+	Overlap     int32
+}
+
+// Similarities correlates the totals of a user with the totals of every
+// other user. The totals are keyed by the rated act, users that rated
+// fewer than minOverlap of the same acts or always gave the same total
+// are left out. The result is ordered from the most to the least similar
+// user.
+func Similarities(totals map[string]float64, othersTotals map[string]map[string]float64, minOverlap int) []Similarity {
+	similarities := make([]Similarity, 0, len(othersTotals))
+	for userId, otherTotals := range othersTotals {
+		var xs, ys []float64
+		for act, total := range totals {
+			otherTotal, ok := otherTotals[act]
+			if !ok {
+				continue
+			}
+
+			xs = append(xs, total)
+			ys = append(ys, otherTotal)
+		}
+
+		if len(xs) < minOverlap {
+			continue
+		}
+
+		correlation, ok := PearsonCorrelation(xs, ys)
+		if !ok {
+			continue
+		}
+
+		similarities = append(similarities, Similarity{
+			UserId:      userId,
+			Correlation: correlation,
+			Overlap:     int32(len(xs)),
+		})
+	}
+
+	sort.Slice(similarities, func(i, j int) bool {
+		a, b := similarities[i], similarities[j]
+		if a.Correlation != b.Correlation {
+			return a.Correlation > b.Correlation
+		}
+
+		if a.Overlap != b.Overlap {
+			return a.Overlap > b.Overlap
+		}
+
+		return a.UserId < b.UserId
+	})
+
+	return similarities
+}
+
+// PearsonCorrelation returns the correlation coefficient of xs and ys. It
+// is undefined if there are fewer than two values or if either side does
+// not vary.
+func PearsonCorrelation(xs []float64, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n < 2 || n != len(ys) {
+		return 0, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var covariance, varianceX, varianceY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
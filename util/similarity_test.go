@@ -0,0 +1,42 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPearsonCorrelation(t *testing.T) {
+	correlation, ok := PearsonCorrelation([]float64{1, 2, 3}, []float64{2, 4, 6})
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, correlation, 1e-9)
+
+	correlation, ok = PearsonCorrelation([]float64{1, 2, 3}, []float64{6, 4, 2})
+	assert.True(t, ok)
+	assert.InDelta(t, -1.0, correlation, 1e-9)
+
+	_, ok = PearsonCorrelation([]float64{1}, []float64{1})
+	assert.False(t, ok, "a single value has no correlation")
+
+	_, ok = PearsonCorrelation([]float64{1, 2, 3}, []float64{5, 5, 5})
+	assert.False(t, ok, "constant values have no correlation")
+}
+
+func TestSimilarities(t *testing.T) {
+	totals := map[string]float64{"a": 10, "b": 20, "c": 30}
+	othersTotals := map[string]map[string]float64{
+		"twin":     {"a": 12, "b": 22, "c": 32},
+		"opposite": {"a": 30, "b": 20, "c": 10},
+		"stranger": {"a": 10, "d": 20},
+		"constant": {"a": 15, "b": 15, "c": 15},
+	}
+
+	similarities := Similarities(totals, othersTotals, 2)
+
+	assert.Len(t, similarities, 2)
+	assert.Equal(t, "twin", similarities[0].UserId)
+	assert.Equal(t, int32(3), similarities[0].Overlap)
+	assert.InDelta(t, 1.0, similarities[0].Correlation, 1e-9)
+	assert.Equal(t, "opposite", similarities[1].UserId)
+	assert.InDelta(t, -1.0, similarities[1].Correlation, 1e-9)
+}
@@ -0,0 +1,105 @@
+package voting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ScoreboardStore reads the ratings a scoreboard is computed from and
+// keeps the frozen scoreboards. It is implemented by db.Queries.
+type ScoreboardStore interface {
+	GetCompetitionSnapshotByCompetitionId(ctx context.Context, competitionID pgtype.UUID) (db.CompetitionSnapshot, error)
+	InsertCompetitionSnapshot(ctx context.Context, arg db.InsertCompetitionSnapshotParams) error
+	ListRatingsByCompetitionId(ctx context.Context, competitionID pgtype.UUID) ([]db.Rating, error)
+	ListActsByCompetitionId(ctx context.Context, competitionID pgtype.UUID) ([]db.ListActsByCompetitionIdRow, error)
+	ListRatingScoresByCompetitionId(ctx context.Context, competitionID pgtype.UUID) ([]db.ListRatingScoresByCompetitionIdRow, error)
+}
+
+// Scoreboard returns the scoreboard of the competition as JSON. It is
+// computed from the ratings while voting is open. Once voting has closed,
+// because the competition was locked or its voting window has passed,
+// the scoreboard is frozen: it is snapshotted on the first read and the
+// snapshot is returned from then on, so that deleted ratings and users no
+// longer change the results.
+func Scoreboard(ctx context.Context, store ScoreboardStore, c db.Competition, now time.Time) ([]byte, error) {
+	if CheckIsOpen(c, now) == nil {
+		scoreboard, err := computeScoreboard(ctx, store, c)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(scoreboard)
+	}
+
+	if err := snapshot(ctx, store, c); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := store.GetCompetitionSnapshotByCompetitionId(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot.Scoreboard, nil
+}
+
+// Freeze snapshots the scoreboard of the competition if voting has closed
+// and it has not been snapshotted yet. It has to be called before ratings
+// of the competition are removed or restored with the competition locked.
+func Freeze(ctx context.Context, store ScoreboardStore, c db.Competition, now time.Time) error {
+	if CheckIsOpen(c, now) == nil {
+		return nil
+	}
+
+	return snapshot(ctx, store, c)
+}
+
+// snapshot stores the current scoreboard of the competition unless a
+// snapshot exists already, which is never replaced.
+func snapshot(ctx context.Context, store ScoreboardStore, c db.Competition) error {
+	_, err := store.GetCompetitionSnapshotByCompetitionId(ctx, c.ID)
+	if err == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	scoreboard, err := computeScoreboard(ctx, store, c)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(scoreboard)
+	if err != nil {
+		return err
+	}
+
+	return store.InsertCompetitionSnapshot(ctx, db.InsertCompetitionSnapshotParams{
+		CompetitionID: c.ID,
+		Scoreboard:    data,
+	})
+}
+
+func computeScoreboard(ctx context.Context, store ScoreboardStore, c db.Competition) (*mapper.ScoreboardResponse, error) {
+	ratings, err := store.ListRatingsByCompetitionId(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	acts, err := store.ListActsByCompetitionId(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := store.ListRatingScoresByCompetitionId(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.FromDbToScoreboardResponse(c, acts, ratings, scores)
+}
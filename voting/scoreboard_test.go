@@ -0,0 +1,121 @@
+package voting
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/hyperremix/song-contest-rater-service/mapper"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScoreboardStore struct {
+	acts      []db.ListActsByCompetitionIdRow
+	ratings   []db.Rating
+	snapshots map[pgtype.UUID][]byte
+}
+
+func (s *fakeScoreboardStore) GetCompetitionSnapshotByCompetitionId(_ context.Context, competitionID pgtype.UUID) (db.CompetitionSnapshot, error) {
+	scoreboard, ok := s.snapshots[competitionID]
+	if !ok {
+		return db.CompetitionSnapshot{}, pgx.ErrNoRows
+	}
+
+	return db.CompetitionSnapshot{CompetitionID: competitionID, Scoreboard: scoreboard}, nil
+}
+
+func (s *fakeScoreboardStore) InsertCompetitionSnapshot(_ context.Context, arg db.InsertCompetitionSnapshotParams) error {
+	if _, ok := s.snapshots[arg.CompetitionID]; !ok {
+		s.snapshots[arg.CompetitionID] = arg.Scoreboard
+	}
+
+	return nil
+}
+
+func (s *fakeScoreboardStore) ListRatingsByCompetitionId(context.Context, pgtype.UUID) ([]db.Rating, error) {
+	return s.ratings, nil
+}
+
+func (s *fakeScoreboardStore) ListActsByCompetitionId(context.Context, pgtype.UUID) ([]db.ListActsByCompetitionIdRow, error) {
+	return s.acts, nil
+}
+
+func (s *fakeScoreboardStore) ListRatingScoresByCompetitionId(context.Context, pgtype.UUID) ([]db.ListRatingScoresByCompetitionIdRow, error) {
+	return nil, nil
+}
+
+func testId(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func newFakeScoreboardStore() *fakeScoreboardStore {
+	return &fakeScoreboardStore{
+		acts: []db.ListActsByCompetitionIdRow{
+			{ID: testId(1), Order: pgtype.Int4{Int32: 1, Valid: true}},
+			{ID: testId(2), Order: pgtype.Int4{Int32: 2, Valid: true}},
+		},
+		ratings: []db.Rating{
+			{ID: testId(10), UserID: testId(20), ActID: testId(1), Total: 50},
+			{ID: testId(11), UserID: testId(20), ActID: testId(2), Total: 40},
+			{ID: testId(12), UserID: testId(21), ActID: testId(1), Total: 30},
+			{ID: testId(13), UserID: testId(21), ActID: testId(2), Total: 45},
+		},
+		snapshots: make(map[pgtype.UUID][]byte),
+	}
+}
+
+func ballots(t *testing.T, scoreboard []byte) int32 {
+	var response mapper.ScoreboardResponse
+	assert.NoError(t, json.Unmarshal(scoreboard, &response))
+	return response.Ballots
+}
+
+func TestScoreboardIsFrozenOnceVotingHasClosed(t *testing.T) {
+	now := time.Date(2025, 5, 17, 23, 0, 0, 0, time.UTC)
+	store := newFakeScoreboardStore()
+	competition := db.Competition{ID: testId(30), VotingClosesAt: pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true}}
+
+	before, err := Scoreboard(context.Background(), store, competition, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), ballots(t, before))
+
+	store.ratings = store.ratings[:2]
+
+	after, err := Scoreboard(context.Background(), store, competition, now)
+	assert.NoError(t, err)
+	assert.Equal(t, before, after, "deleting a rating after the window closed does not change the results")
+}
+
+func TestScoreboardIsLiveWhileVotingIsOpen(t *testing.T) {
+	now := time.Date(2025, 5, 17, 21, 0, 0, 0, time.UTC)
+	store := newFakeScoreboardStore()
+	competition := db.Competition{ID: testId(30), VotingClosesAt: pgtype.Timestamptz{Time: now.Add(time.Minute), Valid: true}}
+
+	before, err := Scoreboard(context.Background(), store, competition, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), ballots(t, before))
+
+	store.ratings = store.ratings[:2]
+
+	after, err := Scoreboard(context.Background(), store, competition, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), ballots(t, after))
+	assert.Empty(t, store.snapshots)
+}
+
+func TestFreezeBeforeRemovingRatings(t *testing.T) {
+	now := time.Date(2025, 5, 17, 23, 0, 0, 0, time.UTC)
+	store := newFakeScoreboardStore()
+	competition := db.Competition{ID: testId(30), VotingClosesAt: pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true}}
+
+	assert.NoError(t, Freeze(context.Background(), store, competition, now))
+	store.ratings = store.ratings[:2]
+
+	scoreboard, err := Scoreboard(context.Background(), store, competition, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), ballots(t, scoreboard), "the first read after the removal returns the frozen scoreboard")
+}
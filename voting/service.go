@@ -0,0 +1,96 @@
+package voting
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+type Service struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		pool:    pool,
+		queries: db.New(pool),
+	}
+}
+
+// Lock closes the competition for rating changes and freezes its
+// scoreboard. The competition row is locked while the snapshot is taken
+// so that no rating can be written between the snapshot and the lock. The
+// scoreboard that was frozen when the voting window passed is kept.
+func (s *Service) Lock(ctx context.Context, competitionId pgtype.UUID) (db.Competition, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return db.Competition{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, competitionId)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	if competition.LockedAt.Valid {
+		return db.Competition{}, echo.NewHTTPError(http.StatusConflict, "competition is already locked")
+	}
+
+	if err := snapshot(ctx, queries, competition); err != nil {
+		return db.Competition{}, err
+	}
+
+	competition, err = queries.UpdateCompetitionLockedAt(ctx, db.UpdateCompetitionLockedAtParams{
+		ID:       competition.ID,
+		LockedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	return competition, tx.Commit(ctx)
+}
+
+// Unlock reopens the competition for rating changes and discards its
+// frozen scoreboard.
+func (s *Service) Unlock(ctx context.Context, competitionId pgtype.UUID) (db.Competition, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return db.Competition{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := s.queries.WithTx(tx)
+
+	competition, err := queries.GetCompetitionByIdForUpdate(ctx, competitionId)
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	if !competition.LockedAt.Valid {
+		return db.Competition{}, echo.NewHTTPError(http.StatusConflict, "competition is not locked")
+	}
+
+	if err := queries.DeleteCompetitionSnapshotByCompetitionId(ctx, competition.ID); err != nil {
+		return db.Competition{}, err
+	}
+
+	competition, err = queries.UpdateCompetitionLockedAt(ctx, db.UpdateCompetitionLockedAtParams{
+		ID:       competition.ID,
+		LockedAt: pgtype.Timestamptz{},
+	})
+	if err != nil {
+		return db.Competition{}, err
+	}
+
+	return competition, tx.Commit(ctx)
+}
@@ -0,0 +1,25 @@
+package voting
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/labstack/echo/v4"
+)
+
+// CheckIsOpen returns an error if ratings of the competition can no
+// longer be created, updated or deleted. A locked competition responds
+// with 423 Locked, a competition whose voting window has passed with
+// 409 Conflict.
+func CheckIsOpen(c db.Competition, now time.Time) error {
+	if c.LockedAt.Valid {
+		return echo.NewHTTPError(http.StatusLocked, "competition is locked")
+	}
+
+	if c.VotingClosesAt.Valid && !now.Before(c.VotingClosesAt.Time) {
+		return echo.NewHTTPError(http.StatusConflict, "voting has closed")
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package voting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hyperremix/song-contest-rater-service/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIsOpen(t *testing.T) {
+	now := time.Date(2025, 5, 17, 21, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, CheckIsOpen(db.Competition{}, now))
+
+	open := db.Competition{VotingClosesAt: pgtype.Timestamptz{Time: now.Add(time.Minute), Valid: true}}
+	assert.NoError(t, CheckIsOpen(open, now))
+
+	closed := db.Competition{VotingClosesAt: pgtype.Timestamptz{Time: now, Valid: true}}
+	assertStatus(t, http.StatusConflict, CheckIsOpen(closed, now))
+
+	locked := db.Competition{LockedAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}}
+	assertStatus(t, http.StatusLocked, CheckIsOpen(locked, now))
+
+	locked.VotingClosesAt = closed.VotingClosesAt
+	assertStatus(t, http.StatusLocked, CheckIsOpen(locked, now))
+}
+
+func assertStatus(t *testing.T, code int, err error) {
+	t.Helper()
+
+	httpErr, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok, "expected an echo.HTTPError") {
+		assert.Equal(t, code, httpErr.Code)
+	}
+}